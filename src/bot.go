@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// =============================================================================
+// Bot token mode
+// =============================================================================
+
+// botUserAgent follows Discord's required format for bot clients.
+const botUserAgent = "DiscordBot (discord-purge, 1.0)"
+
+// walkGuildMessages replaces search for bot tokens, which can't use it: it
+// walks the history of every channel and thread in scope and deletes the
+// target's messages, in bulk where the bot can manage messages. Finished
+// channels and each channel's cursor go into the checkpoint so a resumed run
+// continues where it stopped.
+func (c *DiscordClient) walkGuildMessages(guildID string, options PurgeOptions, checkpoint *Checkpoint) (int, error) {
+	channelIDs := c.filterGuildChannels(options, guildID, c.discoverAllGuildChannelsAndThreads(guildID))
+	fmt.Fprintf(c.out, "   📂 Walking %d channels/threads\n", len(channelIDs))
+
+	totalDeleted := 0
+	for i, chID := range channelIDs {
+		if c.halted() || c.controls.skipping() {
+			break
+		}
+		if checkpoint.CompletedChannels[chID] {
+			continue
+		}
+
+		count, err := c.iterateAndDeleteChannel(guildID, chID, options, checkpoint.ChannelCursors)
+		c.progress.channelScanned()
+		totalDeleted += count
+		if isSafetyHalt(err) {
+			return totalDeleted, err
+		}
+		if err != nil {
+			fmt.Fprintf(c.out, "   ⚠️  Channel %d/%d: %v\n", i+1, len(channelIDs), err)
+			continue
+		}
+		if count > 0 {
+			fmt.Fprintf(c.out, "   ✅ %s %d messages in channel %d/%d\n", options.deletedVerb(), count, i+1, len(channelIDs))
+		}
+		if !c.halted() && !c.controls.skipping() {
+			checkpoint.CompletedChannels[chID] = true
+			delete(checkpoint.ChannelCursors, chID)
+		}
+		c.pacer.Wait(paceDiscovery)
+	}
+	return totalDeleted, nil
+}
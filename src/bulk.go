@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// Bulk deletion
+// =============================================================================
+
+const (
+	// Discord only bulk-deletes messages younger than two weeks; the margin
+	// keeps messages that age out while queued from failing the batch.
+	bulkDeleteMaxAge = 14*24*time.Hour - time.Hour
+
+	minBulkDelete = 2
+	maxBulkDelete = 100
+
+	// Error code for endpoints only bot accounts may use.
+	errorCodeBotsOnly = 20002
+)
+
+// bulkDeleteAllowed reports whether messages in a guild channel or thread
+// can be bulk-deleted: the account must hold Manage Messages there. Each
+// guild's permissions are computed once, the first time a message in it
+// qualifies.
+func (c *DiscordClient) bulkDeleteAllowed(guildID, channelID string) bool {
+	if guildID == "" || c.bulkUnavailable {
+		return false
+	}
+	allowed, checked := c.bulkChannels[guildID]
+	if !checked {
+		allowed = make(map[string]bool)
+		if ids, _, err := c.moderatedChannels(guildID); err == nil {
+			for _, id := range ids {
+				allowed[id] = true
+			}
+		}
+		c.bulkChannels[guildID] = allowed
+	}
+	if len(allowed) == 0 {
+		return false
+	}
+	// Threads inherit their parent channel's permissions.
+	ancestry, _ := c.channelAncestry(guildID, channelID)
+	for _, id := range ancestry {
+		if allowed[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// bulkDeleter queues recent messages per channel and removes them with
+// POST /channels/{id}/messages/bulk-delete, 100 at a time. Messages that
+// can't go through a bulk delete are deleted one by one.
+type bulkDeleter struct {
+	c        *DiscordClient
+	guildID  string
+	pending  map[string][]string
+	deleted  int
+	requests int
+}
+
+func (c *DiscordClient) newBulkDeleter(guildID string) *bulkDeleter {
+	return &bulkDeleter{c: c, guildID: guildID, pending: make(map[string][]string)}
+}
+
+// accepts reports whether a message can be queued for a bulk delete.
+func (b *bulkDeleter) accepts(channelID, messageID string) bool {
+	if time.Since(snowflakeTime(messageID)) >= bulkDeleteMaxAge {
+		return false
+	}
+	return b.c.bulkDeleteAllowed(b.guildID, channelID)
+}
+
+// add queues a message and sends the channel's batch once it is full. It
+// returns the number of messages deleted.
+func (b *bulkDeleter) add(channelID, messageID string) (int, error) {
+	b.pending[channelID] = append(b.pending[channelID], messageID)
+	if len(b.pending[channelID]) < maxBulkDelete {
+		return 0, nil
+	}
+	return b.flushChannel(channelID)
+}
+
+// flush sends every queued batch and returns the number of messages deleted.
+func (b *bulkDeleter) flush() (int, error) {
+	total := 0
+	for channelID := range b.pending {
+		count, err := b.flushChannel(channelID)
+		total += count
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (b *bulkDeleter) flushChannel(channelID string) (int, error) {
+	ids := b.pending[channelID]
+	delete(b.pending, channelID)
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if len(ids) < minBulkDelete || b.c.bulkUnavailable {
+		return b.deleteEach(channelID, ids)
+	}
+
+	payload, _ := json.Marshal(map[string][]string{"messages": ids})
+	body, status, err := b.c.requestWithBody("POST", fmt.Sprintf("/channels/%s/messages/bulk-delete", channelID), string(payload))
+	if err != nil {
+		if isSafetyHalt(err) {
+			return 0, err
+		}
+		fmt.Fprintf(b.c.out, "   ⚠️  Bulk delete failed (%v); deleting one by one.\n", err)
+		time.Sleep(errorBackoffDelay)
+		return b.deleteEach(channelID, ids)
+	}
+	b.c.pacer.Wait(paceDelete)
+
+	switch {
+	case status == 204 || status == 200:
+		b.requests++
+		b.deleted += len(ids)
+		for range ids {
+			b.c.progress.messageDeleted()
+		}
+		return len(ids), nil
+	case parseAPIError(body).Code == errorCodeBotsOnly:
+		// Not available to this account at all; stop trying for the rest of the run.
+		b.c.bulkUnavailable = true
+		detail := formatAPIError(body)
+		if detail == "" {
+			detail = fmt.Sprintf("HTTP %d", status)
+		}
+		fmt.Fprintf(b.c.out, "   ℹ️  Bulk delete isn't available to this account (%s); using single deletes.\n", detail)
+	default:
+		// Typically a message that has just aged out or was already deleted.
+		if detail := formatAPIError(body); detail != "" {
+			fmt.Fprintf(b.c.out, "   ⚠️  Bulk delete returned HTTP %d (%s); deleting one by one.\n", status, detail)
+		} else {
+			fmt.Fprintf(b.c.out, "   ⚠️  Bulk delete returned HTTP %d; deleting one by one.\n", status)
+		}
+	}
+	return b.deleteEach(channelID, ids)
+}
+
+// deleteEach deletes messages with single requests.
+func (b *bulkDeleter) deleteEach(channelID string, ids []string) (int, error) {
+	deleted := 0
+	for _, id := range ids {
+		_, status, err := b.c.request("DELETE", fmt.Sprintf("/channels/%s/messages/%s", channelID, id))
+		if isSafetyHalt(err) {
+			return deleted, err
+		}
+		if err == nil && (status == 204 || status == 200) {
+			deleted++
+			b.c.progress.messageDeleted()
+		} else if err == nil && status != 404 {
+			fmt.Fprintf(b.c.out, "   ⚠️  Cannot delete message %s (HTTP %d)\n", id, status)
+		}
+		b.c.pacer.Wait(paceDelete)
+	}
+	return deleted, nil
+}
+
+// printSummary reports how many messages went through bulk deletes.
+func (b *bulkDeleter) printSummary() {
+	if b.deleted > 0 {
+		fmt.Fprintf(b.c.out, "   🧹 %d messages removed with %d bulk deletes\n", b.deleted, b.requests)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// =============================================================================
+// Run checkpoints
+// =============================================================================
+
+const (
+	checkpointFile          = "discord-purge-checkpoint.json"
+	moderatorCheckpointFile = "discord-purge-moderate-checkpoint.json"
+)
+
+// Checkpoint records which servers and DM channels a run has fully processed,
+// so an interrupted run can pick up where it stopped.
+type Checkpoint struct {
+	UserID              string          `json:"user_id"`
+	TargetAuthorID      string          `json:"target_author_id,omitempty"`
+	SavedAt             time.Time       `json:"saved_at"`
+	Phase               string          `json:"phase"`
+	HaltReason          string          `json:"halt_reason,omitempty"`
+	CompletedGuilds     map[string]bool `json:"completed_guilds"`
+	CompletedDMChannels map[string]bool `json:"completed_dm_channels"`
+	ReactionGuilds      map[string]bool `json:"reaction_guilds"`
+	ReactionDMChannels  map[string]bool `json:"reaction_dm_channels"`
+
+	// Bot runs walk channel histories instead of searching: CompletedChannels
+	// lists finished channels and ChannelCursors the oldest message reached in
+	// the others.
+	CompletedChannels map[string]bool   `json:"completed_channels,omitempty"`
+	ChannelCursors    map[string]string `json:"channel_cursors,omitempty"`
+}
+
+// newCheckpoint returns an empty checkpoint for userID, seeded from a previous
+// checkpoint when resuming.
+func newCheckpoint(userID string, resume *Checkpoint) *Checkpoint {
+	cp := &Checkpoint{
+		UserID:              userID,
+		CompletedGuilds:     make(map[string]bool),
+		CompletedDMChannels: make(map[string]bool),
+		ReactionGuilds:      make(map[string]bool),
+		ReactionDMChannels:  make(map[string]bool),
+		CompletedChannels:   make(map[string]bool),
+		ChannelCursors:      make(map[string]string),
+	}
+	if resume == nil {
+		return cp
+	}
+	for id := range resume.CompletedGuilds {
+		cp.CompletedGuilds[id] = true
+	}
+	for id := range resume.CompletedDMChannels {
+		cp.CompletedDMChannels[id] = true
+	}
+	for id := range resume.ReactionGuilds {
+		cp.ReactionGuilds[id] = true
+	}
+	for id := range resume.ReactionDMChannels {
+		cp.ReactionDMChannels[id] = true
+	}
+	for id := range resume.CompletedChannels {
+		cp.CompletedChannels[id] = true
+	}
+	for id, cursor := range resume.ChannelCursors {
+		cp.ChannelCursors[id] = cursor
+	}
+	return cp
+}
+
+// LoadCheckpoint reads the checkpoint file. It returns nil, nil if none exists.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// Save writes the checkpoint to path.
+func (cp *Checkpoint) Save(path string) error {
+	cp.SavedAt = time.Now()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointPath returns the checkpoint file for a run. Moderator runs keep
+// their own file so they never resume or clear a self-purge checkpoint.
+func checkpointPath(options PurgeOptions) string {
+	if options.TargetAuthorID != "" {
+		return moderatorCheckpointFile
+	}
+	return checkpointFile
+}
+
+// removeCheckpoint deletes the checkpoint file after a run completes.
+func removeCheckpoint(w io.Writer, path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(w, "⚠️  Could not remove checkpoint %s: %v\n", path, err)
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Configuration file and profiles
+// =============================================================================
+
+// Purge phases that a profile can select.
+var allPhases = []string{"1", "2a", "2b", "2c", "3", "4"}
+
+// Config is the on-disk JSON configuration holding named profiles.
+type Config struct {
+	DefaultProfile string              `json:"default_profile"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// Profile holds a reusable set of scope, filter, pacing, output and cleanup
+// settings so repeated runs don't need the interactive prompts.
+type Profile struct {
+	ExcludedGuildIDs          []string         `json:"excluded_guild_ids"`
+	ExcludedDMChannelIDs      []string         `json:"excluded_dm_channel_ids"`
+	ExcludedChannelIDs        []string         `json:"excluded_channel_ids"`
+	ExcludedCategoryIDs       []string         `json:"excluded_category_ids"`
+	ExcludedGuildNamePatterns []string         `json:"excluded_guild_name_patterns"`
+	ExcludedDMRecipients      []string         `json:"excluded_dm_recipients"`
+	ExcludedRelationshipTypes []string         `json:"excluded_relationship_types"`
+	IncludedGuildIDs          []string         `json:"included_guild_ids"`
+	IncludedDMChannelIDs      []string         `json:"included_dm_channel_ids"`
+	IncludedChannelIDs        []string         `json:"included_channel_ids"`
+	DataPackage               string           `json:"data_package"`
+	Filters                   ProfileFilters   `json:"filters"`
+	Preserve                  ProfilePreserve  `json:"preserve"`
+	Retention                 ProfileRetention `json:"retention"`
+	Phases                    []string         `json:"phases"`
+	Pacing                    map[string]int   `json:"pacing"`
+	Output                    ProfileOutput    `json:"output"`
+	Cleanup                   *CleanupChoice   `json:"cleanup"`
+	Daemon                    ProfileDaemon    `json:"daemon"`
+	Moderate                  ProfileModerate  `json:"moderate"`
+	DryRun                    bool             `json:"dry_run"`
+	DMHousekeeping            ProfileDMs       `json:"dm_housekeeping"`
+	LeaveThreads              bool             `json:"leave_threads"`
+}
+
+// ProfileFilters limits deletion to messages sent within a date range.
+// Dates are YYYY-MM-DD or RFC 3339.
+type ProfileFilters struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// ProfilePreserve lists messages that must never be deleted.
+type ProfilePreserve struct {
+	Pinned         bool     `json:"pinned"`
+	MinReactions   int      `json:"min_reactions"`
+	MinReplies     int      `json:"min_replies"`
+	ReactionEmojis []string `json:"reaction_emojis"`
+}
+
+// ProfileRetention keeps the newest messages in every channel and DM, with
+// optional per-server overrides keyed by guild ID.
+type ProfileRetention struct {
+	KeepLast int                        `json:"keep_last"`
+	KeepDays int                        `json:"keep_days"`
+	Guilds   map[string]RetentionLimits `json:"guilds"`
+}
+
+// RetentionLimits is one server's retention override.
+type RetentionLimits struct {
+	KeepLast int `json:"keep_last"`
+	KeepDays int `json:"keep_days"`
+}
+
+// ProfileOutput controls files written by the run.
+type ProfileOutput struct {
+	ReportPath string `json:"report_path"`
+}
+
+// ProfileDaemon configures --daemon mode: every Interval (a Go duration such
+// as "1h"), messages older than RetainDays are deleted.
+type ProfileDaemon struct {
+	RetainDays int    `json:"retain_days"`
+	Interval   string `json:"interval"`
+	StatePath  string `json:"state_path"`
+}
+
+// ProfileModerate configures moderator mode: AuthorID's messages are deleted
+// in the listed servers instead of the account's own.
+type ProfileModerate struct {
+	AuthorID string   `json:"author_id"`
+	Guilds   []string `json:"guilds"`
+}
+
+// ProfileDMs selects the optional DM housekeeping after the purge.
+type ProfileDMs struct {
+	CloseDMs      bool `json:"close_dms"`
+	LeaveGroupDMs bool `json:"leave_group_dms"`
+}
+
+// CleanupChoice selects post-purge cleanup actions without prompting.
+type CleanupChoice struct {
+	RemoveFriends bool `json:"remove_friends"`
+	LeaveServers  bool `json:"leave_servers"`
+	ScrubProfile  bool `json:"scrub_profile"`
+	ClearNotes    bool `json:"clear_notes"`
+	RevokeApps    bool `json:"revoke_apps"`
+
+	// Other relationships, and users whose relationships are never removed
+	// (by ID or username).
+	UnblockUsers    bool     `json:"unblock_users"`
+	CancelOutgoing  bool     `json:"cancel_outgoing_requests"`
+	DeclineIncoming bool     `json:"decline_incoming_requests"`
+	KeepUsers       []string `json:"keep_users"`
+
+	// Servers never left (by ID or name), and new owners for owned servers
+	// (server ID → user ID); owned servers without one are kept.
+	KeepServers       []string          `json:"keep_servers"`
+	TransferOwnership map[string]string `json:"transfer_ownership"`
+}
+
+// any reports whether any cleanup action is selected.
+func (c CleanupChoice) any() bool {
+	return c.LeaveServers || c.ScrubProfile || c.ClearNotes || c.RevokeApps || c.relationshipCleanup().any()
+}
+
+// relationshipCleanup returns the relationship part of the choice.
+func (c CleanupChoice) relationshipCleanup() RelationshipCleanup {
+	return RelationshipCleanup{
+		Friends:  c.RemoveFriends,
+		Blocked:  c.UnblockUsers,
+		Outgoing: c.CancelOutgoing,
+		Incoming: c.DeclineIncoming,
+		Keep:     c.KeepUsers,
+	}
+}
+
+// LoadConfig reads and parses a configuration file. Unknown fields are
+// rejected so typos surface instead of being silently ignored.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening config: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return nil, fmt.Errorf("config %s defines no profiles", path)
+	}
+	return &cfg, nil
+}
+
+// Profile returns the named profile, falling back to the default profile (or
+// the only profile) when name is empty.
+func (cfg *Config) Profile(name string) (string, *Profile, error) {
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+	if name == "" && len(cfg.Profiles) == 1 {
+		for only := range cfg.Profiles {
+			name = only
+		}
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("config has %d profiles; choose one with --profile (%s)", len(cfg.Profiles), strings.Join(cfg.profileNames(), ", "))
+	}
+
+	profile, ok := cfg.Profiles[name]
+	if !ok || profile == nil {
+		return "", nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(cfg.profileNames(), ", "))
+	}
+	return name, profile, nil
+}
+
+func (cfg *Config) profileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks everything that can be checked before authenticating.
+func (p *Profile) Validate() error {
+	var problems []string
+
+	if _, err := parseFilterDate(p.Filters.Before); err != nil {
+		problems = append(problems, fmt.Sprintf("filters.before: %v", err))
+	}
+	if _, err := parseFilterDate(p.Filters.After); err != nil {
+		problems = append(problems, fmt.Sprintf("filters.after: %v", err))
+	}
+
+	before, _ := parseFilterDate(p.Filters.Before)
+	after, _ := parseFilterDate(p.Filters.After)
+	if !before.IsZero() && !after.IsZero() && !after.Before(before) {
+		problems = append(problems, "filters.after must be earlier than filters.before")
+	}
+
+	if p.Preserve.MinReactions < 0 || p.Preserve.MinReplies < 0 {
+		problems = append(problems, "preserve: min_reactions and min_replies cannot be negative")
+	}
+
+	if p.Retention.KeepLast < 0 || p.Retention.KeepDays < 0 {
+		problems = append(problems, "retention: keep_last and keep_days cannot be negative")
+	}
+	keepLast := p.Retention.KeepLast > 0
+	for _, id := range p.retentionGuildIDs() {
+		limits := p.Retention.Guilds[id]
+		if limits.KeepLast < 0 || limits.KeepDays < 0 {
+			problems = append(problems, fmt.Sprintf("retention.guilds.%s: keep_last and keep_days cannot be negative", id))
+		}
+		keepLast = keepLast || limits.KeepLast > 0
+	}
+	// keep_last ranks the messages a search returns, so a lower date bound
+	// would make it keep the newest N of the window rather than of the channel.
+	if keepLast && p.Filters.After != "" {
+		problems = append(problems, "retention keep_last cannot be combined with filters.after")
+	}
+	if keepLast && p.Daemon.RetainDays > 0 {
+		problems = append(problems, "retention keep_last cannot be combined with daemon")
+	}
+
+	if p.Moderate.AuthorID != "" && len(p.Moderate.Guilds) == 0 {
+		problems = append(problems, "moderate.guilds must list the servers to moderate")
+	}
+	if p.Moderate.AuthorID == "" && len(p.Moderate.Guilds) > 0 {
+		problems = append(problems, "moderate.author_id is required with moderate.guilds")
+	}
+	if p.Moderate.AuthorID != "" && (len(p.IncludedGuildIDs) > 0 || len(p.IncludedDMChannelIDs) > 0 || len(p.IncludedChannelIDs) > 0) {
+		problems = append(problems, "moderate.guilds sets the scope; included_* lists cannot be combined with it")
+	}
+	if p.Moderate.AuthorID != "" && p.Daemon.RetainDays > 0 {
+		problems = append(problems, "moderate cannot be combined with daemon")
+	}
+	if p.DryRun && p.Daemon.RetainDays > 0 {
+		problems = append(problems, "dry_run cannot be combined with daemon")
+	}
+
+	if p.Daemon.RetainDays < 0 {
+		problems = append(problems, "daemon.retain_days cannot be negative")
+	}
+	if _, err := p.Daemon.interval(); err != nil {
+		problems = append(problems, fmt.Sprintf("daemon.interval: %v", err))
+	}
+
+	known := make(map[string]bool, len(allPhases))
+	for _, phase := range allPhases {
+		known[phase] = true
+	}
+	for _, phase := range p.Phases {
+		if !known[phase] {
+			problems = append(problems, fmt.Sprintf("phases: unknown phase %q (valid: %s)", phase, strings.Join(allPhases, ", ")))
+		}
+	}
+
+	pacingClasses := make([]string, 0, len(p.Pacing))
+	for class := range p.Pacing {
+		pacingClasses = append(pacingClasses, class)
+	}
+	sort.Strings(pacingClasses)
+	for _, class := range pacingClasses {
+		ms := p.Pacing[class]
+		validClass := false
+		for _, c := range paceClasses {
+			if c == class {
+				validClass = true
+			}
+		}
+		if !validClass {
+			problems = append(problems, fmt.Sprintf("pacing: unknown class %q (valid: %s)", class, strings.Join(paceClasses, ", ")))
+		} else if ms <= 0 {
+			problems = append(problems, fmt.Sprintf("pacing.%s: must be a positive number of milliseconds", class))
+		}
+	}
+
+	for _, pattern := range p.ExcludedGuildNamePatterns {
+		if _, err := compileNamePattern(pattern); err != nil {
+			problems = append(problems, fmt.Sprintf("excluded_guild_name_patterns: %v", err))
+		}
+	}
+	for _, name := range p.ExcludedRelationshipTypes {
+		if _, ok := relationshipTypeNames[strings.ToLower(name)]; !ok {
+			problems = append(problems, fmt.Sprintf("excluded_relationship_types: unknown type %q (valid: friend, blocked, incoming, outgoing, implicit)", name))
+		}
+	}
+
+	var ids []string
+	for _, list := range [][]string{
+		p.ExcludedGuildIDs, p.ExcludedDMChannelIDs, p.ExcludedChannelIDs, p.ExcludedCategoryIDs,
+		p.IncludedGuildIDs, p.IncludedDMChannelIDs, p.IncludedChannelIDs,
+		p.retentionGuildIDs(), p.Moderate.Guilds,
+	} {
+		ids = append(ids, list...)
+	}
+	if p.Moderate.AuthorID != "" {
+		ids = append(ids, p.Moderate.AuthorID)
+	}
+	for _, guildID := range p.transferGuildIDs() {
+		ids = append(ids, guildID, p.Cleanup.TransferOwnership[guildID])
+	}
+	for _, id := range ids {
+		if !isSnowflake(id) {
+			problems = append(problems, fmt.Sprintf("%q is not a valid Discord ID", id))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid profile:\n  • %s", strings.Join(problems, "\n  • "))
+	}
+	return nil
+}
+
+// ValidateGuilds reports profile guild IDs the account is not a member of.
+func (p *Profile) ValidateGuilds(guilds []Guild) error {
+	member := make(map[string]bool, len(guilds))
+	for _, g := range guilds {
+		member[g.ID] = true
+	}
+
+	var unknown []string
+	var ids []string
+	for _, list := range [][]string{p.ExcludedGuildIDs, p.IncludedGuildIDs, p.retentionGuildIDs(), p.Moderate.Guilds, p.transferGuildIDs()} {
+		ids = append(ids, list...)
+	}
+	for _, id := range ids {
+		if !member[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown guild IDs in profile (not a server you are a member of): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// retentionGuildIDs returns the guild IDs with retention overrides, sorted.
+func (p *Profile) retentionGuildIDs() []string {
+	ids := make([]string, 0, len(p.Retention.Guilds))
+	for id := range p.Retention.Guilds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// transferGuildIDs returns the guild IDs with an ownership transfer, sorted.
+func (p *Profile) transferGuildIDs() []string {
+	if p.Cleanup == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(p.Cleanup.TransferOwnership))
+	for id := range p.Cleanup.TransferOwnership {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// PurgeOptions converts the profile into options for PurgeAll.
+func (p *Profile) PurgeOptions() PurgeOptions {
+	options := PurgeOptions{
+		ExcludedGuildIDs:     make(map[string]bool),
+		ExcludedDMChannelIDs: make(map[string]bool),
+	}
+	for _, id := range p.ExcludedGuildIDs {
+		options.ExcludedGuildIDs[id] = true
+	}
+	for _, id := range p.ExcludedDMChannelIDs {
+		options.ExcludedDMChannelIDs[id] = true
+	}
+	options.ExcludedChannelIDs = idSet(p.ExcludedChannelIDs)
+	options.ExcludedCategoryIDs = idSet(p.ExcludedCategoryIDs)
+	for _, pattern := range p.ExcludedGuildNamePatterns {
+		if re, err := compileNamePattern(pattern); err == nil {
+			options.ExcludedGuildNamePatterns = append(options.ExcludedGuildNamePatterns, re)
+		}
+	}
+	options.ExcludedDMRecipients = make(map[string]bool)
+	for _, recipient := range p.ExcludedDMRecipients {
+		options.ExcludedDMRecipients[strings.ToLower(strings.TrimSpace(recipient))] = true
+	}
+	options.ExcludedRelationshipTypes = make(map[int]bool)
+	for _, name := range p.ExcludedRelationshipTypes {
+		options.ExcludedRelationshipTypes[relationshipTypeNames[strings.ToLower(name)]] = true
+	}
+	options.IncludedGuildIDs = idSet(p.IncludedGuildIDs)
+	options.IncludedDMChannelIDs = idSet(p.IncludedDMChannelIDs)
+	options.IncludedChannelIDs = idSet(p.IncludedChannelIDs)
+
+	options.Preserve = PreserveRules{
+		Pinned:         p.Preserve.Pinned,
+		MinReactions:   p.Preserve.MinReactions,
+		MinReplies:     p.Preserve.MinReplies,
+		ReactionEmojis: p.Preserve.ReactionEmojis,
+	}
+
+	options.Retention = RetentionRule{KeepLast: p.Retention.KeepLast, KeepDays: p.Retention.KeepDays}
+	if len(p.Retention.Guilds) > 0 {
+		options.GuildRetention = make(map[string]RetentionRule, len(p.Retention.Guilds))
+		for id, limits := range p.Retention.Guilds {
+			options.GuildRetention[id] = RetentionRule{KeepLast: limits.KeepLast, KeepDays: limits.KeepDays}
+		}
+	}
+
+	options.Before, _ = parseFilterDate(p.Filters.Before)
+	options.After, _ = parseFilterDate(p.Filters.After)
+
+	if len(p.Phases) > 0 {
+		options.Phases = make(map[string]bool)
+		for _, phase := range p.Phases {
+			options.Phases[phase] = true
+		}
+	}
+
+	options.ReportPath = p.Output.ReportPath
+	options.DryRun = p.DryRun
+	options.CloseDMs = p.DMHousekeeping.CloseDMs
+	options.LeaveGroupDMs = p.DMHousekeeping.LeaveGroupDMs
+	options.LeaveThreads = p.LeaveThreads
+	if p.Moderate.AuthorID != "" {
+		options = moderationOptions(options, p.Moderate.AuthorID, p.Moderate.Guilds)
+	}
+	return options
+}
+
+// ApplyPacing overrides the pacer's starting delays with the profile values.
+func (p *Profile) ApplyPacing(pacer *Pacer) {
+	for class, ms := range p.Pacing {
+		pacer.SetDelay(class, time.Duration(ms)*time.Millisecond)
+	}
+}
+
+// relationshipTypeNames maps config names to relationship types.
+var relationshipTypeNames = map[string]int{
+	"friend":   RelationshipFriend,
+	"blocked":  RelationshipBlocked,
+	"incoming": RelationshipIncomingReq,
+	"outgoing": RelationshipOutgoingReq,
+	"implicit": RelationshipImplicit,
+}
+
+// compileNamePattern compiles a case-insensitive name pattern. Patterns
+// prefixed with "re:" are regular expressions; anything else is a glob where
+// * matches any run of characters and ? matches one character.
+func compileNamePattern(pattern string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", expr, err)
+		}
+		return re, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func parseFilterDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC 3339)", value)
+	}
+	return t, nil
+}
+
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func isSnowflake(id string) bool {
+	if len(id) < 15 || len(id) > 21 {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
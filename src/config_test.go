@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateKeepLastWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr string
+	}{
+		{name: "keep_last alone", profile: Profile{Retention: ProfileRetention{KeepLast: 10}}},
+		{name: "keep_days with after", profile: Profile{Retention: ProfileRetention{KeepDays: 30}, Filters: ProfileFilters{After: "2024-01-01"}}},
+		{
+			name:    "keep_last with after",
+			profile: Profile{Retention: ProfileRetention{KeepLast: 10}, Filters: ProfileFilters{After: "2024-01-01"}},
+			wantErr: "filters.after",
+		},
+		{
+			name:    "per-server keep_last with daemon",
+			profile: Profile{Retention: ProfileRetention{Guilds: map[string]RetentionLimits{"123456789012345678": {KeepLast: 5}}}, Daemon: ProfileDaemon{RetainDays: 7}},
+			wantErr: "daemon",
+		},
+	}
+	for _, tt := range tests {
+		err := tt.profile.Validate()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), "keep_last cannot be combined with "+tt.wantErr)):
+			t.Errorf("%s: error = %v, want one about %s", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateTransferOwnership(t *testing.T) {
+	const member, stranger = "123456789012345678", "223456789012345678"
+	profile := Profile{Cleanup: &CleanupChoice{TransferOwnership: map[string]string{member: "not-a-user"}}}
+	if err := profile.Validate(); err == nil || !strings.Contains(err.Error(), `"not-a-user" is not a valid Discord ID`) {
+		t.Errorf("Validate() = %v, want an invalid target user ID", err)
+	}
+
+	guilds := []Guild{{ID: member}}
+	profile.Cleanup.TransferOwnership = map[string]string{member: "323456789012345678"}
+	if err := profile.ValidateGuilds(guilds); err != nil {
+		t.Errorf("ValidateGuilds() = %v for a member server", err)
+	}
+	profile.Cleanup.TransferOwnership[stranger] = "323456789012345678"
+	if err := profile.ValidateGuilds(guilds); err == nil || !strings.Contains(err.Error(), stranger) {
+		t.Errorf("ValidateGuilds() = %v, want %s reported", err, stranger)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// Runtime controls
+// =============================================================================
+
+// Controls holds the pause / skip / abort requests made while PurgeAll runs,
+// from the keyboard or (on Unix) from SIGUSR1, SIGUSR2 and SIGQUIT.
+type Controls struct {
+	paused atomic.Bool
+	skip   atomic.Bool
+	abort  atomic.Bool
+
+	// active is set while PurgeAll runs; control signals arriving outside a
+	// run (e.g. between daemon cycles) are ignored.
+	active      atomic.Bool
+	signalsOnce sync.Once
+}
+
+const controlsHelp = "⌨️  Controls: p = pause/resume, s = skip current server/DM, i = status, q = stop after current item (letter + Enter)"
+
+// TogglePause pauses or resumes the run and reports the new state.
+func (ctl *Controls) TogglePause() bool {
+	for {
+		old := ctl.paused.Load()
+		if ctl.paused.CompareAndSwap(old, !old) {
+			return !old
+		}
+	}
+}
+
+// Skip abandons the current server, DM or channel.
+func (ctl *Controls) Skip() {
+	ctl.skip.Store(true)
+}
+
+// Abort stops the run once the current item is finished.
+func (ctl *Controls) Abort() {
+	ctl.abort.Store(true)
+}
+
+// skipping reports whether the current item should be abandoned; loops over
+// messages and channels check it and break out.
+func (ctl *Controls) skipping() bool {
+	return ctl.skip.Load()
+}
+
+// waitWhilePaused blocks while the run is paused, or until stop returns true.
+func (ctl *Controls) waitWhilePaused(stop func() bool) {
+	for ctl.paused.Load() && !stop() {
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// beginItem marks the start of a server, DM or channel: it clears a pending
+// skip, turns a pending abort into a stop request, and updates progress.
+func (c *DiscordClient) beginItem(name string) {
+	c.controls.skip.Store(false)
+	if c.controls.abort.Load() {
+		c.RequestStop()
+	}
+	c.progress.startItem(name)
+}
+
+// endItem clears a skip request once its item is finished, notes it in the
+// log and reports whether the item was skipped, so callers don't record it
+// as completed.
+func (c *DiscordClient) endItem() bool {
+	if c.controls.skip.Swap(false) {
+		fmt.Fprintln(c.out, "   ⏭️  Skipped on request.")
+		return true
+	}
+	return false
+}
+
+// printControlStatus prints a status snapshot for a control action.
+func (c *DiscordClient) printControlStatus(action string) {
+	fmt.Fprintf(c.out, "   🎛️  %s — %s\n", action, formatStatusLine(c.progress.Snapshot(), time.Now()))
+}
+
+// handleControl applies one keyboard command.
+func (c *DiscordClient) handleControl(command string) {
+	switch strings.ToLower(strings.TrimSpace(command)) {
+	case "p", "pause", "r", "resume":
+		if c.controls.TogglePause() {
+			c.printControlStatus("Paused (p to resume)")
+		} else {
+			c.printControlStatus("Resumed")
+		}
+	case "s", "skip":
+		c.controls.Skip()
+		c.printControlStatus("Skipping the current item")
+	case "i", "status":
+		state := "Running"
+		if c.controls.paused.Load() {
+			state = "Paused"
+		}
+		c.printControlStatus(state)
+	case "q", "quit", "stop":
+		c.controls.Abort()
+		c.controls.paused.Store(false)
+		c.printControlStatus("Stopping after the current item")
+	case "":
+	default:
+		fmt.Fprintln(c.out, "   "+controlsHelp)
+	}
+}
+
+// startControls listens for keyboard commands (when stdin is a terminal) and
+// control signals until the returned function is called.
+func (c *DiscordClient) startControls() func() {
+	c.controls.skip.Store(false)
+	c.controls.abort.Store(false)
+	c.controls.paused.Store(false)
+	c.controls.active.Store(true)
+
+	done := make(chan struct{})
+	if isTerminal(os.Stdin) {
+		fmt.Fprintln(c.out, controlsHelp)
+		fmt.Fprintln(c.out)
+		lines := stdinLineChan()
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case line, ok := <-lines:
+					if !ok {
+						return
+					}
+					c.handleControl(line)
+				}
+			}
+		}()
+	} else if help := controlSignalsHelp(); help != "" {
+		fmt.Fprintln(c.out, help)
+		fmt.Fprintln(c.out)
+	}
+	stopSignals := c.watchControlSignals()
+
+	return func() {
+		c.controls.active.Store(false)
+		close(done)
+		stopSignals()
+	}
+}
+
+var (
+	stdinOnce  sync.Once
+	stdinLines chan string
+)
+
+// stdinLineChan returns the lines typed on stdin. Once runtime controls have
+// read from stdin, every later prompt must read through here too, or the
+// control reader would swallow its answer.
+func stdinLineChan() <-chan string {
+	stdinOnce.Do(func() {
+		stdinLines = make(chan string)
+		go func() {
+			reader := bufio.NewReader(os.Stdin)
+			for {
+				line, err := reader.ReadString('\n')
+				if line != "" {
+					stdinLines <- strings.TrimRight(line, "\r\n")
+				}
+				if err != nil {
+					close(stdinLines)
+					return
+				}
+			}
+		}()
+	})
+	return stdinLines
+}
+
+// readInputLine reads one line from stdin through stdinLineChan.
+func readInputLine() string {
+	return <-stdinLineChan()
+}
@@ -0,0 +1,9 @@
+//go:build unix && !(darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// statusSignals is empty where SIGINFO doesn't exist (e.g. Linux); a double
+// SIGUSR1 asks for the status instead.
+var statusSignals []os.Signal
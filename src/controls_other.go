@@ -0,0 +1,17 @@
+//go:build !unix
+
+package main
+
+// controlSignalsHelp is empty where control signals don't exist.
+func controlSignalsHelp() string {
+	return ""
+}
+
+// registerControlSignals is a no-op where SIGUSR1 and SIGUSR2 don't exist.
+func (c *DiscordClient) registerControlSignals() {}
+
+// watchControlSignals is a no-op where SIGUSR1, SIGUSR2 and SIGQUIT don't
+// exist (e.g. Windows); use the keyboard controls instead.
+func (c *DiscordClient) watchControlSignals() func() {
+	return func() {}
+}
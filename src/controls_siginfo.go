@@ -0,0 +1,11 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// statusSignals print a status snapshot; SIGINFO is also sent by Ctrl+T.
+var statusSignals = []os.Signal{syscall.SIGINFO}
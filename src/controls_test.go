@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestEndItemReportsSkip(t *testing.T) {
+	client := NewDiscordClient("token")
+	client.progress.start()
+	client.progress.startPhase("1", 2)
+
+	client.beginItem("first")
+	client.handleControl("s")
+	if !client.endItem() {
+		t.Fatal("endItem did not report the skip")
+	}
+	client.beginItem("second")
+	if client.endItem() {
+		t.Fatal("the skip carried over to the next item")
+	}
+}
+
+func TestPurgeStatsSkippedIsIncomplete(t *testing.T) {
+	stats := PurgeStats{SkippedDMChannels: []string{"1"}}
+	if !stats.failed() {
+		t.Error("a run with a skipped DM counts as complete")
+	}
+	if got, want := stats.failureSummary(), "1 DMs skipped"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
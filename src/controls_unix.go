@@ -0,0 +1,93 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// statusSignalWindow is how soon a second SIGUSR1 must follow the first to
+// ask for a status snapshot instead of pausing.
+const statusSignalWindow = time.Second
+
+// controlSignalsHelp describes the control signals for headless runs.
+func controlSignalsHelp() string {
+	help := fmt.Sprintf("🎛️  Signals (pid %d): USR1 = pause/resume, USR1 twice within a second = status, USR2 = skip current server/DM, QUIT = stop after current item", os.Getpid())
+	if len(statusSignals) > 0 {
+		help += ", INFO = status"
+	}
+	return help
+}
+
+// registerControlSignals catches SIGUSR1 (pause/resume, or status when sent
+// twice within statusSignalWindow), SIGUSR2 (skip) and, where it exists,
+// SIGINFO (status) for the rest of the process. The default action of the
+// first two kills the process, so they stay registered between runs (e.g.
+// between daemon cycles) and are ignored while no run is active.
+func (c *DiscordClient) registerControlSignals() {
+	c.controls.signalsOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, append([]os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}, statusSignals...)...)
+		go func() {
+			// pending fires when a lone SIGUSR1 has waited out the window.
+			var pending <-chan time.Time
+			for {
+				select {
+				case sig := <-signals:
+					if !c.controls.active.Load() {
+						pending = nil
+						continue
+					}
+					switch sig {
+					case syscall.SIGUSR1:
+						if pending != nil {
+							pending = nil
+							c.handleControl("i")
+						} else {
+							pending = time.After(statusSignalWindow)
+						}
+					case syscall.SIGUSR2:
+						c.handleControl("s")
+					default:
+						c.handleControl("i")
+					}
+				case <-pending:
+					pending = nil
+					if c.controls.active.Load() {
+						c.handleControl("p")
+					}
+				}
+			}
+		}()
+	})
+}
+
+// watchControlSignals maps SIGUSR1 to pause/resume, SIGUSR2 to skip and
+// SIGQUIT to stop after the current item, for headless runs. SIGQUIT is only
+// caught until the returned function is called; a second SIGQUIT falls back
+// to Go's default (exit with a stack dump).
+func (c *DiscordClient) watchControlSignals() func() {
+	c.registerControlSignals()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGQUIT)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-done:
+		case <-signals:
+			signal.Reset(syscall.SIGQUIT)
+			c.handleControl("q")
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,54 @@
+//go:build unix
+
+package main
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestControlSignalsOutsideRun(t *testing.T) {
+	client := NewDiscordClient("token")
+	client.registerControlSignals()
+
+	// No run is active: the signal must neither kill the process nor pause.
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if client.controls.paused.Load() {
+		t.Fatal("SIGUSR1 paused with no run active")
+	}
+
+	stop := client.startControls()
+	defer stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !client.controls.paused.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("SIGUSR1 did not pause the active run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestControlSignalDoubleUSR1PrintsStatus(t *testing.T) {
+	client := NewDiscordClient("token")
+	stop := client.startControls()
+	defer stop()
+
+	// Two SIGUSR1 within the window ask for the status and don't pause.
+	for i := 0; i < 2; i++ {
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	time.Sleep(statusSignalWindow + 200*time.Millisecond)
+	if client.controls.paused.Load() {
+		t.Fatal("a double SIGUSR1 paused the run")
+	}
+}
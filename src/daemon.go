@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// =============================================================================
+// Retention daemon
+// =============================================================================
+
+const (
+	daemonStateFile       = "discord-purge-daemon.json"
+	defaultDaemonInterval = time.Hour
+	minDaemonInterval     = time.Minute
+
+	// Each cycle re-checks this much history before the previous cutoff, so
+	// messages the search index picked up late are not skipped forever.
+	daemonCursorOverlap = time.Hour
+)
+
+// daemonPhases are the phases a daemon cycle runs when the profile doesn't
+// list any. Hidden DMs (2b) would be re-opened on every cycle, the data
+// package (2c) doesn't change, and reactions (3) need a full channel scan.
+var daemonPhases = []string{"1", "2a"}
+
+// DaemonState is the daemon's persisted cursor and health record. LastCutoff
+// is the cutoff of the last successful cycle; later cycles only search
+// messages newer than it. A cycle where any server, DM or listing failed is
+// not successful.
+type DaemonState struct {
+	UserID              string    `json:"user_id"`
+	LastCutoff          time.Time `json:"last_cutoff"`
+	Cycles              int       `json:"cycles"`
+	TotalDeleted        int       `json:"total_deleted"`
+	LastRunAt           time.Time `json:"last_run_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	NextRunAt           time.Time `json:"next_run_at"`
+}
+
+// LoadDaemonState reads the daemon state file. It returns an empty state if
+// none exists.
+func LoadDaemonState(path string) (*DaemonState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &DaemonState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading daemon state: %w", err)
+	}
+
+	var state DaemonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing daemon state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes the daemon state to path.
+func (s *DaemonState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding daemon state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing daemon state: %w", err)
+	}
+	return nil
+}
+
+// interval returns the configured cycle interval, or the default.
+func (d ProfileDaemon) interval() (time.Duration, error) {
+	if d.Interval == "" {
+		return defaultDaemonInterval, nil
+	}
+	interval, err := time.ParseDuration(d.Interval)
+	if err != nil {
+		return 0, err
+	}
+	if interval < minDaemonInterval {
+		return 0, fmt.Errorf("must be at least %s", minDaemonInterval)
+	}
+	return interval, nil
+}
+
+// daemonLog prints a timestamped health line.
+func (c *DiscordClient) daemonLog(format string, args ...any) {
+	fmt.Fprintf(c.out, "[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// runDaemon repeats the purge on the profile's schedule, deleting messages
+// older than daemon.retain_days, until SIGINT/SIGTERM or a safety halt. It
+// returns the process exit code.
+func runDaemon(client *DiscordClient, profile *Profile, dataPackagePath string) int {
+	interval, _ := profile.Daemon.interval()
+
+	// The first signal stops the current cycle at its next request (saving a
+	// checkpoint) or ends the wait between cycles.
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	// SIGUSR1/SIGUSR2 must not kill the daemon between cycles.
+	client.registerControlSignals()
+	go func() {
+		sig := <-signals
+		client.daemonLog("🛑 Received %s, shutting down after the current request...", sig)
+		client.RequestStop()
+		close(stop)
+	}()
+
+	return daemonLoop(client, profile, dataPackagePath, interval, stop)
+}
+
+// daemonLoop runs a cycle every interval until stop is closed (after a stop
+// request on the client) or a safety halt, and returns the exit code.
+func daemonLoop(client *DiscordClient, profile *Profile, dataPackagePath string, interval time.Duration, stop <-chan struct{}) int {
+	retain := time.Duration(profile.Daemon.RetainDays) * 24 * time.Hour
+	statePath := profile.Daemon.StatePath
+	if statePath == "" {
+		statePath = daemonStateFile
+	}
+
+	state, err := LoadDaemonState(statePath)
+	if err != nil {
+		fmt.Fprintf(client.out, "❌ %v\n", err)
+		return 1
+	}
+	if state.UserID != client.userID {
+		state = &DaemonState{UserID: client.userID}
+	}
+
+	client.daemonLog("🔁 Daemon started: deleting messages older than %d days every %s (state: %s)", profile.Daemon.RetainDays, interval, statePath)
+	if !state.LastCutoff.IsZero() {
+		client.daemonLog("   Resuming from cursor %s", state.LastCutoff.Local().Format("2006-01-02 15:04"))
+	}
+
+	for {
+		cutoff := time.Now().Add(-retain)
+		options := profile.PurgeOptions()
+		options.NoEstimate = true
+		if len(profile.Phases) == 0 {
+			options.Phases = make(map[string]bool)
+			for _, phase := range daemonPhases {
+				options.Phases[phase] = true
+			}
+		}
+		if options.Before.IsZero() || cutoff.Before(options.Before) {
+			options.Before = cutoff
+		}
+		if !state.LastCutoff.IsZero() {
+			if since := state.LastCutoff.Add(-daemonCursorOverlap); since.After(options.After) {
+				options.After = since
+			}
+		}
+
+		state.Cycles++
+		state.LastRunAt = time.Now()
+		client.daemonLog("▶️  Cycle %d: messages sent before %s", state.Cycles, cutoff.Local().Format("2006-01-02 15:04"))
+
+		// Re-authenticating doubles as a health check of the token and network.
+		var stats PurgeStats
+		if err = client.Authenticate(); err == nil {
+			stats = client.PurgeAll(dataPackagePath, options)
+		}
+
+		switch {
+		case client.halted() && client.halt.Kind == haltStopped:
+			state.NextRunAt = time.Time{}
+			client.saveDaemonState(state, statePath)
+			client.daemonLog("⏹️  Daemon stopped during cycle %d; the cursor was not advanced.", state.Cycles)
+			return 0
+		case client.halted():
+			state.LastError = client.halt.Error()
+			state.ConsecutiveFailures++
+			state.NextRunAt = time.Time{}
+			client.saveDaemonState(state, statePath)
+			client.daemonLog("🛑 Daemon halted: %v", client.halt)
+			return 2
+		case err != nil:
+			state.LastError = err.Error()
+			state.ConsecutiveFailures++
+			client.daemonLog("❌ Cycle %d failed (%d in a row): %v", state.Cycles, state.ConsecutiveFailures, err)
+		case stats.failed():
+			// Part of the window wasn't searched; the next cycle covers it again.
+			state.LastError = stats.failureSummary()
+			state.ConsecutiveFailures++
+			state.TotalDeleted += stats.TotalMessagesDeleted
+			client.daemonLog("❌ Cycle %d incomplete (%d in a row), the cursor was not advanced: %s",
+				state.Cycles, state.ConsecutiveFailures, state.LastError)
+		default:
+			state.LastCutoff = cutoff
+			state.LastSuccessAt = time.Now()
+			state.LastError = ""
+			state.ConsecutiveFailures = 0
+			state.TotalDeleted += stats.TotalMessagesDeleted
+			client.daemonLog("💓 Cycle %d finished in %s: %d messages deleted (%d since the daemon was set up)",
+				state.Cycles, stats.TimeElapsed, stats.TotalMessagesDeleted, state.TotalDeleted)
+		}
+
+		state.NextRunAt = time.Now().Add(interval)
+		client.saveDaemonState(state, statePath)
+		client.daemonLog("⏰ Next cycle at %s", state.NextRunAt.Local().Format("2006-01-02 15:04:05"))
+
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			state.NextRunAt = time.Time{}
+			client.saveDaemonState(state, statePath)
+			client.daemonLog("⏹️  Daemon stopped.")
+			return 0
+		}
+	}
+}
+
+func (c *DiscordClient) saveDaemonState(state *DaemonState, path string) {
+	if err := state.Save(path); err != nil {
+		c.daemonLog("⚠️  %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDaemonAPI serves one server and no DMs. Every GET /users/@me after the
+// first starts a new daemon cycle; the guild searches of each cycle are
+// recorded, and cycles listed in failSearch answer them with HTTP 500.
+type fakeDaemonAPI struct {
+	failSearch map[int]bool
+	onCycle    func(cycle int)
+
+	mu       sync.Mutex
+	cycle    int
+	searches map[int][]searchBounds
+}
+
+type searchBounds struct {
+	maxID, minID string
+}
+
+func (f *fakeDaemonAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/users/@me":
+		// The first request only authenticated, so cycle 1 is the second.
+		if f.cycle > 0 && f.onCycle != nil {
+			f.onCycle(f.cycle)
+		}
+		f.cycle++
+		writeJSON(w, 200, User{ID: "111111111111111111", Username: "tester"})
+	case r.URL.Path == "/users/@me/guilds":
+		writeJSON(w, 200, []Guild{{ID: "222222222222222222", Name: "Guild"}})
+	case strings.HasSuffix(r.URL.Path, "/messages/search"):
+		cycle := f.cycle - 1
+		query := r.URL.Query()
+		f.searches[cycle] = append(f.searches[cycle], searchBounds{query.Get("max_id"), query.Get("min_id")})
+		if f.failSearch[cycle] {
+			writeJSON(w, 500, map[string]string{"message": "internal error"})
+			return
+		}
+		writeJSON(w, 200, SearchResult{})
+	default:
+		writeJSON(w, 200, []any{})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// chdirTemp runs the test in a temporary directory, since runs write their
+// pacing, checkpoint and snapshot files to the working directory.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestDaemonLoopCursor(t *testing.T) {
+	dir := chdirTemp(t)
+	statePath := filepath.Join(dir, "daemon.json")
+
+	client := NewDiscordClient("token")
+	stop := make(chan struct{})
+	api := &fakeDaemonAPI{failSearch: map[int]bool{2: true}, searches: make(map[int][]searchBounds)}
+	var afterFailure *DaemonState
+	api.onCycle = func(cycle int) {
+		switch cycle {
+		case 3:
+			// The daemon saved its state before waiting for this cycle.
+			state, err := LoadDaemonState(statePath)
+			if err != nil {
+				t.Errorf("loading state after the failed cycle: %v", err)
+			}
+			afterFailure = state
+		case 4:
+			client.RequestStop()
+			close(stop)
+		}
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client.baseURL = server.URL
+	if err := client.Authenticate(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile := &Profile{Daemon: ProfileDaemon{RetainDays: 7, StatePath: statePath}}
+	start := time.Now()
+	if code := daemonLoop(client, profile, "", 10*time.Millisecond, stop); code != 0 {
+		t.Fatalf("daemonLoop returned %d, want 0", code)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	for cycle := 1; cycle <= 3; cycle++ {
+		if len(api.searches[cycle]) == 0 {
+			t.Fatalf("cycle %d sent no guild search", cycle)
+		}
+	}
+	if len(api.searches[4]) != 0 {
+		t.Errorf("cycle 4 searched after the stop request: %v", api.searches[4])
+	}
+	first, failed, retried := api.searches[1][0], api.searches[2][0], api.searches[3][0]
+
+	// Cycle 1 has no cursor and searches everything older than retain_days.
+	retain := 7 * 24 * time.Hour
+	if first.minID != "" {
+		t.Errorf("cycle 1 min_id = %q, want none", first.minID)
+	}
+	cutoff1 := snowflakeTime(first.maxID)
+	if cutoff1.Before(start.Add(-retain-time.Second)) || cutoff1.After(time.Now().Add(-retain)) {
+		t.Errorf("cycle 1 max_id %s is %s, want about %s", first.maxID, cutoff1, start.Add(-retain))
+	}
+
+	// Cycle 2 starts from cycle 1's cutoff minus the overlap, and fails.
+	if want := snowflakeFromTime(cutoff1.Add(-daemonCursorOverlap)); failed.minID != want {
+		t.Errorf("cycle 2 min_id = %s, want %s", failed.minID, want)
+	}
+	if afterFailure == nil {
+		t.Fatal("state after the failed cycle was not checked")
+	}
+	if got := snowflakeFromTime(afterFailure.LastCutoff); got != first.maxID {
+		t.Errorf("cursor after the failed cycle = %s, want cycle 1's cutoff %s", got, first.maxID)
+	}
+	if afterFailure.ConsecutiveFailures != 1 || afterFailure.LastError == "" {
+		t.Errorf("failed cycle recorded %d failures, error %q; want 1 and an error", afterFailure.ConsecutiveFailures, afterFailure.LastError)
+	}
+
+	// Cycle 3 searches the same window again and succeeds.
+	if retried.minID != failed.minID {
+		t.Errorf("cycle 3 min_id = %s, want %s (cursor must not advance after a failure)", retried.minID, failed.minID)
+	}
+	if !snowflakeTime(retried.maxID).After(cutoff1) {
+		t.Errorf("cycle 3 max_id %s is not newer than cycle 1's %s", retried.maxID, first.maxID)
+	}
+
+	state, err := LoadDaemonState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := snowflakeFromTime(state.LastCutoff); got != retried.maxID {
+		t.Errorf("saved cursor = %s, want cycle 3's cutoff %s", got, retried.maxID)
+	}
+	if state.Cycles != 4 {
+		t.Errorf("cycles = %d, want 4", state.Cycles)
+	}
+	if state.ConsecutiveFailures != 0 || state.LastError != "" {
+		t.Errorf("after a successful cycle: %d failures, error %q", state.ConsecutiveFailures, state.LastError)
+	}
+	if !state.NextRunAt.IsZero() {
+		t.Errorf("next run = %s after the daemon stopped, want none", state.NextRunAt)
+	}
+}
+
+func TestDaemonLoopStopsBetweenCycles(t *testing.T) {
+	dir := chdirTemp(t)
+	statePath := filepath.Join(dir, "daemon.json")
+
+	client := NewDiscordClient("token")
+	stop := make(chan struct{})
+	api := &fakeDaemonAPI{searches: make(map[int][]searchBounds)}
+	// Closed during cycle 1 without stopping the client: the cycle finishes
+	// and the daemon then ends instead of waiting an hour.
+	api.onCycle = func(cycle int) {
+		if cycle == 1 {
+			close(stop)
+		}
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client.baseURL = server.URL
+	if err := client.Authenticate(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile := &Profile{Daemon: ProfileDaemon{RetainDays: 1, StatePath: statePath}}
+	done := make(chan int)
+	go func() { done <- daemonLoop(client, profile, "", time.Hour, stop) }()
+	select {
+	case code := <-done:
+		if code != 0 {
+			t.Fatalf("daemonLoop returned %d, want 0", code)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+
+	state, err := LoadDaemonState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if state.Cycles != 1 || len(api.searches[1]) == 0 {
+		t.Fatalf("cycles = %d with %d searches, want 1 finished cycle", state.Cycles, len(api.searches[1]))
+	}
+	if got := snowflakeFromTime(state.LastCutoff); got != api.searches[1][0].maxID {
+		t.Errorf("saved cursor = %s, want the cycle's cutoff %s", got, api.searches[1][0].maxID)
+	}
+	if !state.NextRunAt.IsZero() {
+		t.Errorf("next run = %s after the daemon stopped, want none", state.NextRunAt)
+	}
+}
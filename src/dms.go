@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// =============================================================================
+// DM housekeeping
+// =============================================================================
+
+const dmSnapshotFile = "discord-purge-dm-snapshot.json"
+
+// DMSnapshot records the DM list before Phase 2b and the DMs it re-opens, so
+// they can be closed again even when the run is interrupted. It is saved after
+// every re-opened DM and removed once they are all closed.
+type DMSnapshot struct {
+	UserID   string    `json:"user_id"`
+	SavedAt  time.Time `json:"saved_at"`
+	Open     []string  `json:"open"`
+	Reopened []string  `json:"reopened"`
+
+	known   map[string]bool
+	saveErr error
+}
+
+// newDMSnapshot records the currently open DM channels. DMs still listed as
+// re-opened in an existing snapshot at path (e.g. ones an earlier restore
+// couldn't close) are carried over rather than overwritten, and a snapshot
+// belonging to another account is left alone with an error.
+func newDMSnapshot(path, userID string, open []Channel) (*DMSnapshot, error) {
+	previous, err := LoadDMSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.UserID != userID {
+		return nil, fmt.Errorf("%s belongs to another account", path)
+	}
+
+	s := &DMSnapshot{UserID: userID, known: make(map[string]bool)}
+	if previous != nil {
+		for _, id := range previous.Reopened {
+			if !s.known[id] {
+				s.known[id] = true
+				s.Reopened = append(s.Reopened, id)
+			}
+		}
+	}
+	for _, ch := range open {
+		if !s.known[ch.ID] {
+			s.Open = append(s.Open, ch.ID)
+			s.known[ch.ID] = true
+		}
+	}
+	return s, nil
+}
+
+// LoadDMSnapshot reads the DM snapshot. It returns nil, nil if none exists.
+func LoadDMSnapshot(path string) (*DMSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading DM snapshot: %w", err)
+	}
+
+	var s DMSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing DM snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// Save writes the snapshot to path.
+func (s *DMSnapshot) Save(path string) error {
+	s.SavedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding DM snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing DM snapshot: %w", err)
+	}
+	return nil
+}
+
+// recordOpened notes a DM that Phase 2b opened. DMs that were open already,
+// or recorded before, are ignored. Only the first failed save is returned.
+func (s *DMSnapshot) recordOpened(channelID string) error {
+	if s.known[channelID] {
+		return nil
+	}
+	s.known[channelID] = true
+	s.Reopened = append(s.Reopened, channelID)
+	if err := s.Save(dmSnapshotFile); err != nil && s.saveErr == nil {
+		s.saveErr = err
+		return err
+	}
+	return nil
+}
+
+// recloseDMs closes the DMs the snapshot lists as re-opened. The snapshot is
+// removed once all of them are closed and keeps the rest otherwise. It
+// returns the number closed.
+func (c *DiscordClient) recloseDMs(s *DMSnapshot) int {
+	var remaining []string
+	for _, id := range s.Reopened {
+		if c.halted() {
+			remaining = append(remaining, id)
+			continue
+		}
+		if err := c.CloseDMChannel(id, false); err != nil {
+			if !isSafetyHalt(err) {
+				fmt.Fprintf(c.out, "   ⚠️  Failed to close re-opened DM %s: %v\n", id, err)
+			}
+			remaining = append(remaining, id)
+		}
+		c.pacer.Wait(paceDiscovery)
+	}
+	closed := len(s.Reopened) - len(remaining)
+	s.Reopened = remaining
+
+	if len(remaining) == 0 {
+		if err := os.Remove(dmSnapshotFile); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(c.out, "⚠️  Could not remove %s: %v\n", dmSnapshotFile, err)
+		}
+	} else if err := s.Save(dmSnapshotFile); err != nil {
+		fmt.Fprintf(c.out, "⚠️  %v\n", err)
+	}
+	return closed
+}
+
+// restoreDMSnapshot closes the DMs left open by an interrupted Phase 2b and
+// returns the number closed.
+func (c *DiscordClient) restoreDMSnapshot() int {
+	s, err := LoadDMSnapshot(dmSnapshotFile)
+	if err != nil {
+		fmt.Fprintf(c.out, "⚠️  %v\n", err)
+		return 0
+	}
+	if s == nil {
+		return 0
+	}
+	if s.UserID != c.userID {
+		fmt.Fprintf(c.out, "⚠️  %s belongs to another account; leaving it alone.\n\n", dmSnapshotFile)
+		return 0
+	}
+
+	fmt.Fprintf(c.out, "🧹 Closing %d DMs re-opened by an interrupted run...\n", len(s.Reopened))
+	closed := c.recloseDMs(s)
+	if len(s.Reopened) > 0 {
+		fmt.Fprintf(c.out, "   ✅ Closed %d; %d are still listed in %s\n", closed, len(s.Reopened), dmSnapshotFile)
+	} else {
+		fmt.Fprintf(c.out, "   ✅ Closed %d\n", closed)
+	}
+	fmt.Fprintln(c.out)
+	return closed
+}
+
+// DMHousekeepingResult records the DM list changes made after the purge.
+type DMHousekeepingResult struct {
+	Reclosed     int `json:"reclosed"`
+	Closed       int `json:"closed"`
+	GroupDMsLeft int `json:"group_dms_left"`
+}
+
+// CloseDMChannel closes a DM, hiding it from the DM list, or leaves a group
+// DM. Silent leaves don't announce the departure to the group.
+func (c *DiscordClient) CloseDMChannel(channelID string, silent bool) error {
+	path := fmt.Sprintf("/channels/%s", channelID)
+	if silent {
+		path += "?silent=true"
+	}
+	_, status, err := c.request("DELETE", path)
+	if err != nil {
+		return err
+	}
+	if status == 200 || status == 204 || status == 404 {
+		return nil
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
+
+// dmHousekeeping tidies the DM list after the purge: with CloseDMs every
+// purged DM is closed, and with LeaveGroupDMs every purged group DM is left
+// silently. A dry run only lists them. reclosed is the number of re-opened
+// DMs already closed again, which the result includes.
+func (c *DiscordClient) dmHousekeeping(reclosed int, purged map[string]bool, known map[string]Channel, options PurgeOptions) *DMHousekeepingResult {
+	result := &DMHousekeepingResult{Reclosed: reclosed}
+	if !options.CloseDMs && !options.LeaveGroupDMs {
+		if reclosed == 0 {
+			return nil
+		}
+		return result
+	}
+	fmt.Fprintln(c.out, "🧹 Tidying your DM list...")
+
+	ids := make([]string, 0, len(purged))
+	for id := range purged {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if c.halted() {
+			break
+		}
+		ch, ok := known[id]
+		if !ok {
+			// Data package channels are only known by ID.
+			fetched, err := c.GetChannel(id)
+			c.pacer.Wait(paceDiscovery)
+			if err != nil {
+				continue
+			}
+			ch = *fetched
+		}
+		label := describeChannel(ch)
+
+		switch {
+		case ch.Type == ChannelTypeGroupDM && options.LeaveGroupDMs:
+			if options.DryRun {
+				fmt.Fprintf(c.out, "   🧪 Would leave group DM: %s\n", label)
+				result.GroupDMsLeft++
+				continue
+			}
+			if err := c.CloseDMChannel(id, true); err != nil {
+				fmt.Fprintf(c.out, "   ⚠️  Failed to leave group DM %s: %v\n", label, err)
+			} else {
+				fmt.Fprintf(c.out, "   ✅ Left group DM: %s\n", label)
+				result.GroupDMsLeft++
+			}
+		case ch.Type == ChannelTypeDM && options.CloseDMs:
+			if options.DryRun {
+				fmt.Fprintf(c.out, "   🧪 Would close DM: %s\n", label)
+				result.Closed++
+				continue
+			}
+			if err := c.CloseDMChannel(id, false); err != nil {
+				fmt.Fprintf(c.out, "   ⚠️  Failed to close DM %s: %v\n", label, err)
+			} else {
+				fmt.Fprintf(c.out, "   ✅ Closed DM: %s\n", label)
+				result.Closed++
+			}
+		default:
+			continue
+		}
+		c.pacer.Wait(paceDiscovery)
+	}
+	fmt.Fprintln(c.out)
+	return result
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewDMSnapshotKeepsReopened(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dmSnapshotFile)
+	previous := &DMSnapshot{UserID: "1", Open: []string{"10"}, Reopened: []string{"20", "30"}}
+	if err := previous.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// 20 is still open from the interrupted run; 40 is a new open DM.
+	s, err := newDMSnapshot(path, "1", []Channel{{ID: "10"}, {ID: "20"}, {ID: "40"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"20", "30"}; !reflect.DeepEqual(s.Reopened, want) {
+		t.Errorf("reopened = %v, want %v", s.Reopened, want)
+	}
+	if want := []string{"10", "40"}; !reflect.DeepEqual(s.Open, want) {
+		t.Errorf("open = %v, want %v", s.Open, want)
+	}
+	if err := s.recordOpened("30"); err != nil || len(s.Reopened) != 2 {
+		t.Errorf("recording a carried-over DM again: %v, reopened %v", err, s.Reopened)
+	}
+}
+
+func TestNewDMSnapshotOtherAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dmSnapshotFile)
+	previous := &DMSnapshot{UserID: "2", Reopened: []string{"20"}}
+	if err := previous.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newDMSnapshot(path, "1", nil); err == nil {
+		t.Fatal("took over another account's snapshot")
+	}
+	kept, err := LoadDMSnapshot(path)
+	if err != nil || kept.UserID != "2" || len(kept.Reopened) != 1 {
+		t.Fatalf("snapshot changed: %+v, %v", kept, err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// =============================================================================
+// Per-server identity reset
+// =============================================================================
+
+// serverIdentityFields returns the per-server profile fields set on a member,
+// in the order they are reported.
+func serverIdentityFields(member *GuildMember) []string {
+	var fields []string
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"nick", member.Nick},
+		{"avatar", member.Avatar},
+		{"banner", member.Banner},
+		{"bio", member.Bio},
+	} {
+		if field.value != nil && *field.value != "" {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+// resetServerIdentity clears the account's nickname, server avatar, banner
+// and bio in a guild. It returns the fields that were reset, or in a dry run
+// the fields that would be; servers where nothing is set aren't written to.
+func (c *DiscordClient) resetServerIdentity(guildID string, options PurgeOptions) ([]string, error) {
+	member, err := c.GetOwnGuildMember(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching your server profile: %w", err)
+	}
+	fields := serverIdentityFields(member)
+	if len(fields) == 0 || options.DryRun {
+		return fields, nil
+	}
+
+	reset := make(map[string]any, len(fields))
+	for _, field := range fields {
+		reset[field] = nil
+	}
+	payload, err := json.Marshal(reset)
+	if err != nil {
+		return nil, fmt.Errorf("encoding server profile: %w", err)
+	}
+	body, status, err := c.requestWithBody("PATCH", fmt.Sprintf("/guilds/%s/members/@me", guildID), string(payload))
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		if detail := formatAPIError(body); detail != "" {
+			return nil, fmt.Errorf("HTTP %d, %s", status, detail)
+		}
+		return nil, fmt.Errorf("HTTP %d", status)
+	}
+	return fields, nil
+}
+
+// serverStat returns the stats entry for a guild, adding one if the guild
+// has none yet.
+func serverStat(stats *[]ServerStat, guildID, name string) *ServerStat {
+	for i := range *stats {
+		if (*stats)[i].GuildID == guildID {
+			return &(*stats)[i]
+		}
+	}
+	*stats = append(*stats, ServerStat{GuildID: guildID, GuildName: name})
+	return &(*stats)[len(*stats)-1]
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// Server leaving
+// =============================================================================
+
+// ServerLeavePlan sorts the account's servers for the server-leaving cleanup.
+type ServerLeavePlan struct {
+	Leave    []Guild           // servers to leave
+	Kept     []Guild           // outside the purge scope or on the keep-list
+	Owned    []Guild           // owned servers, left only after a transfer
+	Transfer map[string]string // owned server ID → new owner's user ID
+}
+
+// ServerLeaveResult records what the server-leaving cleanup did.
+type ServerLeaveResult struct {
+	Left                 []string `json:"left"`
+	OwnershipTransferred []string `json:"ownership_transferred,omitempty"`
+	OwnedKept            []string `json:"owned_kept,omitempty"`
+	Kept                 int      `json:"kept"`
+}
+
+// keepsServer reports whether a server is on the keep-list, by ID or name.
+func keepsServer(keep []string, guild Guild) bool {
+	for _, entry := range keep {
+		if entry == guild.ID || strings.EqualFold(entry, guild.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// planServerLeave decides which servers to leave. Servers the purge didn't
+// fully cover (excluded, or only some channels selected) and servers on the
+// keep-list stay; owned servers can't be left and need a new owner first.
+func (c *DiscordClient) planServerLeave(options PurgeOptions, choice CleanupChoice) (*ServerLeavePlan, error) {
+	guilds, err := c.GetAllGuilds()
+	if err != nil {
+		return nil, fmt.Errorf("fetching guilds: %w", err)
+	}
+
+	plan := &ServerLeavePlan{Transfer: make(map[string]string)}
+	for _, guild := range guilds {
+		switch {
+		case !options.guildInScope(guild) || !options.wholeGuildInScope(guild.ID) || keepsServer(choice.KeepServers, guild):
+			plan.Kept = append(plan.Kept, guild)
+		case guild.Owner:
+			plan.Owned = append(plan.Owned, guild)
+			if newOwner := choice.TransferOwnership[guild.ID]; newOwner != "" {
+				plan.Transfer[guild.ID] = newOwner
+			}
+		default:
+			plan.Leave = append(plan.Leave, guild)
+		}
+	}
+	return plan, nil
+}
+
+// leaveCount returns how many servers the plan leaves.
+func (p *ServerLeavePlan) leaveCount() int {
+	return len(p.Leave) + len(p.Transfer)
+}
+
+// print lists the plan's servers.
+func (p *ServerLeavePlan) print() {
+	fmt.Printf("🚪 Servers to leave (%d):\n", p.leaveCount())
+	for _, guild := range p.Leave {
+		fmt.Printf("   • %s\n", displayGuildName(guild))
+	}
+	for _, guild := range p.Owned {
+		if newOwner := p.Transfer[guild.ID]; newOwner != "" {
+			fmt.Printf("   👑 %s — ownership goes to %s first\n", displayGuildName(guild), newOwner)
+		} else {
+			fmt.Printf("   👑 %s — you own it; kept\n", displayGuildName(guild))
+		}
+	}
+	if len(p.Kept) > 0 {
+		fmt.Printf("   ↪ Keeping %d servers outside the purge scope or on your keep-list\n", len(p.Kept))
+	}
+}
+
+// promptServerLeave offers to transfer each owned server, lists the plan and
+// asks for confirmation.
+func promptServerLeave(plan *ServerLeavePlan) bool {
+	if len(plan.Owned) > 0 {
+		fmt.Println("You own some of these servers. Owners can't leave, but ownership can be")
+		fmt.Println("transferred to another member first.")
+		for _, guild := range plan.Owned {
+			fmt.Printf("Transfer %s to (user ID; Enter to keep the server): ", displayGuildName(guild))
+			newOwner := strings.TrimSpace(readInputLine())
+			switch {
+			case newOwner == "":
+			case isSnowflake(newOwner):
+				plan.Transfer[guild.ID] = newOwner
+			default:
+				fmt.Printf("   %q is not a valid user ID; keeping %s.\n", newOwner, displayGuildName(guild))
+			}
+		}
+		fmt.Println()
+	}
+
+	plan.print()
+	if plan.leaveCount() == 0 {
+		return false
+	}
+	fmt.Println()
+	fmt.Printf("Leave these %d servers? (yes/no): ", plan.leaveCount())
+	response := strings.TrimSpace(strings.ToLower(readInputLine()))
+	return response == "yes" || response == "y"
+}
+
+// TransferGuildOwnership makes another member the owner of a server.
+func (c *DiscordClient) TransferGuildOwnership(guildID, newOwnerID string) error {
+	payload, _ := json.Marshal(map[string]string{"owner_id": newOwnerID})
+	body, status, err := c.requestWithBody("PATCH", fmt.Sprintf("/guilds/%s", guildID), string(payload))
+	if err != nil {
+		return err
+	}
+	if status == 200 {
+		return nil
+	}
+	if detail := formatAPIError(body); detail != "" {
+		return fmt.Errorf("HTTP %d, %s", status, detail)
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
+
+// LeaveServers carries out a plan: owned servers with a new owner are
+// transferred and then left, other owned servers are kept.
+func (c *DiscordClient) LeaveServers(plan *ServerLeavePlan) (*ServerLeaveResult, error) {
+	result := &ServerLeaveResult{Kept: len(plan.Kept)}
+
+	leave := append([]Guild(nil), plan.Leave...)
+	for _, guild := range plan.Owned {
+		name := displayGuildName(guild)
+		newOwner := plan.Transfer[guild.ID]
+		if newOwner == "" {
+			result.OwnedKept = append(result.OwnedKept, name)
+			continue
+		}
+		err := c.TransferGuildOwnership(guild.ID, newOwner)
+		c.pacer.Wait(paceDiscovery)
+		if isSafetyHalt(err) {
+			return result, err
+		}
+		if err != nil {
+			fmt.Fprintf(c.out, "   ⚠️  Failed to transfer %s to %s: %v — kept\n", name, newOwner, err)
+			result.OwnedKept = append(result.OwnedKept, name)
+			continue
+		}
+		fmt.Fprintf(c.out, "   👑 Transferred %s to %s\n", name, newOwner)
+		result.OwnershipTransferred = append(result.OwnershipTransferred, name)
+		leave = append(leave, guild)
+	}
+
+	for _, guild := range leave {
+		name := displayGuildName(guild)
+		err := c.LeaveGuild(guild.ID)
+		if isSafetyHalt(err) {
+			return result, err
+		}
+		if err != nil {
+			fmt.Fprintf(c.out, "   ⚠️  Failed to leave server %s: %v\n", name, err)
+		} else {
+			result.Left = append(result.Left, name)
+			fmt.Fprintf(c.out, "   ✅ Left server: %s\n", name)
+		}
+		c.pacer.Wait(paceDiscovery)
+	}
+	return result, nil
+}
@@ -9,8 +9,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,14 +24,9 @@ import (
 const (
 	apiBase = "https://discord.com/api/v9"
 
-	// Conservative pacing to reduce transient 400/429 churn.
-	searchDelay          = 350 * time.Millisecond
-	deleteDelay          = 350 * time.Millisecond
-	reactionDelay        = 350 * time.Millisecond
-	batchDelay           = 350 * time.Millisecond
-	threadDiscoveryDelay = 350 * time.Millisecond
-	threadArchiveDelay   = 350 * time.Millisecond
-	errorBackoffDelay    = 1250 * time.Millisecond
+	// Per-class request pacing is adaptive (see pacer.go); this fixed backoff
+	// only applies after unexpected errors.
+	errorBackoffDelay = 1250 * time.Millisecond
 
 	maxSearchIndexWaits = 40
 )
@@ -65,17 +63,61 @@ type DiscordClient struct {
 	httpClient *http.Client
 	userID     string
 	username   string
+	baseURL    string
+	bot        bool
+	halt       *SafetyHaltError
+	pacer      *Pacer
+	progress   *Progress
+	controls   *Controls
+	out        *console
+
+	// stopRequested is set by RequestStop and turned into a halt by the next
+	// request, so only the run's own goroutine ever writes halt.
+	stopRequested atomic.Bool
+
+	// channelParents caches each known channel's parent (its category, or the
+	// parent channel of a thread) for scope rules; indexedGuilds records
+	// which guilds have been indexed.
+	channelParents map[string]string
+	indexedGuilds  map[string]bool
+
+	// unresolvedChannels records channels whose lookup failed, so it isn't
+	// repeated; unresolvedReported records the channels already reported as
+	// skipped because of it.
+	unresolvedChannels map[string]bool
+	unresolvedReported map[string]bool
+
+	// bulkChannels caches, per guild, the channels where bulk deletes are
+	// allowed; bulkUnavailable is set once Discord refuses them to this
+	// account.
+	bulkChannels    map[string]map[string]bool
+	bulkUnavailable bool
+
+	// joinedThreads records, per guild, the threads discovery found the
+	// current user a member of. A guild has an entry once discovery ran.
+	joinedThreads map[string]map[string]Channel
 }
 
 type User struct {
 	ID            string `json:"id"`
 	Username      string `json:"username"`
 	Discriminator string `json:"discriminator"`
+	Bot           bool   `json:"bot,omitempty"`
 }
 
 type Guild struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Owner bool   `json:"owner"`
+}
+
+// PermissionOverwrite is a channel's allow/deny override for a role
+// (Type 0) or a member (Type 1). Permission sets are decimal strings.
+type PermissionOverwrite struct {
+	ID    string `json:"id"`
+	Type  int    `json:"type"`
+	Allow string `json:"allow"`
+	Deny  string `json:"deny"`
 }
 
 type Channel struct {
@@ -83,8 +125,15 @@ type Channel struct {
 	Type           int         `json:"type"`
 	Name           string      `json:"name"`
 	GuildID        string      `json:"guild_id"`
+	ParentID       string      `json:"parent_id"`
+	MessageCount   int         `json:"message_count,omitempty"`
 	Recipients     []User      `json:"recipients"`
 	ThreadMetadata *ThreadMeta `json:"thread_metadata,omitempty"`
+
+	// Member is set on threads the current user has joined.
+	Member *ThreadMember `json:"member,omitempty"`
+
+	PermissionOverwrites []PermissionOverwrite `json:"permission_overwrites,omitempty"`
 }
 
 type ThreadMeta struct {
@@ -92,18 +141,41 @@ type ThreadMeta struct {
 	ArchiveTimestamp string `json:"archive_timestamp"`
 }
 
+// ThreadMember is the current user's membership of a thread; ID is the
+// thread's ID.
+type ThreadMember struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+}
+
 type Message struct {
 	ID        string     `json:"id"`
 	Author    User       `json:"author"`
 	ChannelID string     `json:"channel_id"`
 	Hit       bool       `json:"hit,omitempty"`
+	Pinned    bool       `json:"pinned"`
 	Reactions []Reaction `json:"reactions,omitempty"`
+	Thread    *Channel   `json:"thread,omitempty"`
+	Poll      *Poll      `json:"poll,omitempty"`
 }
 
+// Reaction is one emoji's reactions on a message. Count includes super
+// reactions (burst), which are also counted separately in BurstCount.
 type Reaction struct {
-	Count int       `json:"count"`
-	Me    bool      `json:"me"`
-	Emoji EmojiInfo `json:"emoji"`
+	Count        int                  `json:"count"`
+	CountDetails ReactionCountDetails `json:"count_details"`
+	Me           bool                 `json:"me"`
+	MeBurst      bool                 `json:"me_burst"`
+	BurstCount   int                  `json:"burst_count"`
+	BurstColors  []string             `json:"burst_colors,omitempty"`
+	Emoji        EmojiInfo            `json:"emoji"`
+}
+
+// ReactionCountDetails splits a reaction's count into super and normal
+// reactions.
+type ReactionCountDetails struct {
+	Burst  int `json:"burst"`
+	Normal int `json:"normal"`
 }
 
 type EmojiInfo struct {
@@ -111,6 +183,25 @@ type EmojiInfo struct {
 	Name string  `json:"name"` // unicode character or custom emoji name
 }
 
+// Reaction types, as used by the reaction endpoints.
+const (
+	ReactionTypeNormal = 0
+	ReactionTypeBurst  = 1
+)
+
+// placedTypes returns the types of the current user's reactions with this
+// emoji; a normal reaction and a super reaction can both be present.
+func (r Reaction) placedTypes() []int {
+	var types []int
+	if r.Me {
+		types = append(types, ReactionTypeNormal)
+	}
+	if r.MeBurst {
+		types = append(types, ReactionTypeBurst)
+	}
+	return types
+}
+
 type SearchResult struct {
 	TotalResults int         `json:"total_results"`
 	Messages     [][]Message `json:"messages"`
@@ -135,8 +226,24 @@ type Relationship struct {
 }
 
 type ThreadListResponse struct {
-	Threads []Channel `json:"threads"`
-	HasMore bool      `json:"has_more"`
+	Threads []Channel      `json:"threads"`
+	Members []ThreadMember `json:"members"`
+	HasMore bool           `json:"has_more"`
+}
+
+// threads returns the listed threads, marking the ones the current user has
+// joined with their membership.
+func (r ThreadListResponse) threads() []Channel {
+	joined := make(map[string]*ThreadMember, len(r.Members))
+	for i := range r.Members {
+		joined[r.Members[i].ID] = &r.Members[i]
+	}
+	for i := range r.Threads {
+		if member := joined[r.Threads[i].ID]; member != nil && r.Threads[i].Member == nil {
+			r.Threads[i].Member = member
+		}
+	}
+	return r.Threads
 }
 
 // =============================================================================
@@ -145,10 +252,21 @@ type ThreadListResponse struct {
 
 func NewDiscordClient(token string) *DiscordClient {
 	return &DiscordClient{
-		token: token,
+		token:   token,
+		baseURL: apiBase,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		pacer:              NewPacer(),
+		progress:           NewProgress(),
+		controls:           &Controls{},
+		out:                newConsole(os.Stdout),
+		channelParents:     make(map[string]string),
+		indexedGuilds:      make(map[string]bool),
+		unresolvedChannels: make(map[string]bool),
+		unresolvedReported: make(map[string]bool),
+		bulkChannels:       make(map[string]map[string]bool),
+		joinedThreads:      make(map[string]map[string]Channel),
 	}
 }
 
@@ -157,20 +275,33 @@ func (c *DiscordClient) request(method, path string) ([]byte, int, error) {
 }
 
 func (c *DiscordClient) requestWithBody(method, path, jsonBody string) ([]byte, int, error) {
+	c.controls.waitWhilePaused(c.stopRequested.Load)
+	if c.halt == nil && c.stopRequested.Load() {
+		c.halt = &SafetyHaltError{Kind: haltStopped, Method: method, Path: path, Detail: "stop requested"}
+	}
+	if c.halt != nil {
+		return nil, 0, c.halt
+	}
+
 	for attempt := 0; attempt < 5; attempt++ {
 		var bodyReader io.Reader
 		if jsonBody != "" {
 			bodyReader = strings.NewReader(jsonBody)
 		}
 
-		req, err := http.NewRequest(method, apiBase+path, bodyReader)
+		req, err := http.NewRequest(method, c.baseURL+path, bodyReader)
 		if err != nil {
 			return nil, 0, fmt.Errorf("creating request: %w", err)
 		}
 
-		req.Header.Set("Authorization", c.token)
+		if c.bot {
+			req.Header.Set("Authorization", "Bot "+c.token)
+			req.Header.Set("User-Agent", botUserAgent)
+		} else {
+			req.Header.Set("Authorization", c.token)
+			req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
@@ -180,6 +311,16 @@ func (c *DiscordClient) requestWithBody(method, path, jsonBody string) ([]byte,
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 
+		// Cloudflare bans, captchas and verification blocks must stop the run
+		// rather than being retried or treated as ordinary failures.
+		if haltErr := classifySafetyResponse(method, path, resp.StatusCode, body); haltErr != nil {
+			c.halt = haltErr
+			return body, resp.StatusCode, haltErr
+		}
+
+		class := classifyRequest(method, path)
+		c.pacer.Observe(class, resp.StatusCode == 429)
+
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			return body, resp.StatusCode, nil
 		}
@@ -222,7 +363,8 @@ func (c *DiscordClient) requestWithBody(method, path, jsonBody string) ([]byte,
 				scope = " (global)"
 			}
 
-			fmt.Printf("   ⏳ Rate limited%s on %s %s, waiting %.1f seconds (attempt %d/5)...\n", scope, method, path, waitTime, attempt+1)
+			fmt.Fprintf(c.out, "   ⏳ Rate limited%s on %s %s, waiting %.1f seconds (attempt %d/5)...\n", scope, method, path, waitTime, attempt+1)
+			c.progress.rateLimited(method+" "+path, time.Duration(waitTime*1000)*time.Millisecond)
 			time.Sleep(time.Duration(waitTime*1000) * time.Millisecond)
 			continue
 		}
@@ -292,16 +434,47 @@ func previousSnowflakeID(id string) string {
 	return strconv.FormatUint(n-1, 10)
 }
 
+// discordEpochMs is the first millisecond of 2015, the snowflake epoch.
+const discordEpochMs = 1420070400000
+
+// snowflakeFromTime returns the smallest snowflake ID created at t.
+func snowflakeFromTime(t time.Time) string {
+	ms := t.UnixMilli() - discordEpochMs
+	if ms < 0 {
+		ms = 0
+	}
+	return strconv.FormatUint(uint64(ms)<<22, 10)
+}
+
+// snowflakeTime returns the creation time encoded in a snowflake ID.
+func snowflakeTime(id string) time.Time {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(n>>22) + discordEpochMs)
+}
+
 // =============================================================================
 // Discord API methods — Authentication & Discovery
 // =============================================================================
 
+// Authenticate identifies the account behind the token. A token prefixed
+// with "Bot " (or any token when c.bot is preset by --bot) is a bot token;
+// everything else is sent as a user token only.
 func (c *DiscordClient) Authenticate() error {
+	if token, ok := strings.CutPrefix(c.token, "Bot "); ok {
+		c.token = strings.TrimSpace(token)
+		c.bot = true
+	}
+
 	body, status, err := c.request("GET", "/users/@me")
 	if err != nil {
 		return fmt.Errorf("authentication request failed: %w", err)
 	}
-
+	if status == 401 && !c.bot {
+		return fmt.Errorf("invalid token — authentication failed (HTTP 401); for a bot token, prefix it with \"Bot \" or pass --bot")
+	}
 	if status == 401 {
 		return fmt.Errorf("invalid token — authentication failed (HTTP 401)")
 	}
@@ -316,6 +489,7 @@ func (c *DiscordClient) Authenticate() error {
 
 	c.userID = user.ID
 	c.username = user.Username
+	c.bot = user.Bot
 	return nil
 }
 
@@ -353,7 +527,7 @@ func (c *DiscordClient) GetAllGuilds() ([]Guild, error) {
 			break
 		}
 
-		time.Sleep(batchDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
 	return allGuilds, nil
@@ -437,6 +611,24 @@ func (c *DiscordClient) GetGuildChannels(guildID string) ([]Channel, error) {
 	return channels, nil
 }
 
+// GetChannel fetches a single channel or thread by ID.
+func (c *DiscordClient) GetChannel(channelID string) (*Channel, error) {
+	body, status, err := c.request("GET", fmt.Sprintf("/channels/%s", channelID))
+	if err != nil {
+		return nil, fmt.Errorf("fetching channel: %w", err)
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("fetching channel: HTTP %d — %s", status, formatAPIError(body))
+	}
+
+	var ch Channel
+	if err := json.Unmarshal(body, &ch); err != nil {
+		return nil, fmt.Errorf("parsing channel: %w", err)
+	}
+
+	return &ch, nil
+}
+
 // GetActiveGuildThreads fetches all active threads in a guild.
 func (c *DiscordClient) GetActiveGuildThreads(guildID string) ([]Channel, error) {
 	body, status, err := c.request("GET", fmt.Sprintf("/guilds/%s/threads/active", guildID))
@@ -455,7 +647,7 @@ func (c *DiscordClient) GetActiveGuildThreads(guildID string) ([]Channel, error)
 		return nil, fmt.Errorf("parsing active threads: %w", err)
 	}
 
-	return result.Threads, nil
+	return result.threads(), nil
 }
 
 // GetArchivedPublicThreads fetches all archived public threads for a channel.
@@ -496,7 +688,13 @@ func (c *DiscordClient) GetJoinedArchivedPrivateThreads(channelID string) ([]Cha
 			return allThreads, fmt.Errorf("parsing joined archived private threads: %w", err)
 		}
 
-		allThreads = append(allThreads, result.Threads...)
+		// Every thread listed here has been joined.
+		for _, t := range result.threads() {
+			if t.Member == nil {
+				t.Member = &ThreadMember{ID: t.ID, UserID: c.userID}
+			}
+			allThreads = append(allThreads, t)
+		}
 
 		if !result.HasMore || len(result.Threads) == 0 {
 			break
@@ -509,7 +707,7 @@ func (c *DiscordClient) GetJoinedArchivedPrivateThreads(channelID string) ([]Cha
 			break
 		}
 
-		time.Sleep(batchDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
 	return allThreads, nil
@@ -541,7 +739,7 @@ func (c *DiscordClient) getArchivedThreads(channelID, kind string) ([]Channel, e
 			return allThreads, fmt.Errorf("parsing archived threads: %w", err)
 		}
 
-		allThreads = append(allThreads, result.Threads...)
+		allThreads = append(allThreads, result.threads()...)
 
 		if !result.HasMore || len(result.Threads) == 0 {
 			break
@@ -555,7 +753,7 @@ func (c *DiscordClient) getArchivedThreads(channelID, kind string) ([]Channel, e
 			break
 		}
 
-		time.Sleep(batchDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
 	return allThreads, nil
@@ -584,6 +782,7 @@ func (c *DiscordClient) discoverAllGuildChannelsAndThreads(guildID string) []str
 	// Filter to text-capable channel types and collect parent channels
 	var parentChannelIDs []string
 	for _, ch := range channels {
+		c.channelParents[ch.ID] = ch.ParentID
 		switch ch.Type {
 		case ChannelTypeGuildText, ChannelTypeGuildNews:
 			addChannel(ch.ID)
@@ -603,46 +802,160 @@ func (c *DiscordClient) discoverAllGuildChannelsAndThreads(guildID string) []str
 	activeThreads, err := c.GetActiveGuildThreads(guildID)
 	if err == nil {
 		for _, t := range activeThreads {
+			c.noteThread(guildID, t)
 			addChannel(t.ID)
 		}
 	}
 
 	// Get archived public + private threads for each parent channel
 	for _, parentID := range parentChannelIDs {
+		if c.halted() || c.controls.skipping() {
+			break
+		}
 		pubThreads, err := c.GetArchivedPublicThreads(parentID)
 		if err == nil {
 			for _, t := range pubThreads {
+				c.noteThread(guildID, t)
 				addChannel(t.ID)
 			}
 		}
-		time.Sleep(threadArchiveDelay)
+		c.pacer.Wait(paceDiscovery)
 
 		privThreads, err := c.GetArchivedPrivateThreads(parentID)
 		if err == nil {
 			for _, t := range privThreads {
+				c.noteThread(guildID, t)
 				addChannel(t.ID)
 			}
 		}
-		time.Sleep(threadArchiveDelay)
+		c.pacer.Wait(paceDiscovery)
 
 		joinedPrivThreads, err := c.GetJoinedArchivedPrivateThreads(parentID)
 		if err == nil {
 			for _, t := range joinedPrivThreads {
+				c.noteThread(guildID, t)
 				addChannel(t.ID)
 			}
 		}
 
-		time.Sleep(threadDiscoveryDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
+	if c.joinedThreads[guildID] == nil {
+		c.joinedThreads[guildID] = make(map[string]Channel)
+	}
 	return channelIDs
 }
 
+// noteThread records a discovered thread's parent and, if the current user
+// has joined it, its membership.
+func (c *DiscordClient) noteThread(guildID string, t Channel) {
+	c.channelParents[t.ID] = t.ParentID
+	if t.Member == nil {
+		return
+	}
+	if c.joinedThreads[guildID] == nil {
+		c.joinedThreads[guildID] = make(map[string]Channel)
+	}
+	c.joinedThreads[guildID][t.ID] = t
+}
+
+// indexGuildChannels records the parent of every channel and active thread in
+// a guild, once per guild.
+func (c *DiscordClient) indexGuildChannels(guildID string) {
+	if c.indexedGuilds[guildID] {
+		return
+	}
+	c.indexedGuilds[guildID] = true
+
+	channels, err := c.GetGuildChannels(guildID)
+	if err == nil {
+		for _, ch := range channels {
+			c.channelParents[ch.ID] = ch.ParentID
+		}
+	}
+	threads, err := c.GetActiveGuildThreads(guildID)
+	if err == nil {
+		for _, t := range threads {
+			c.channelParents[t.ID] = t.ParentID
+		}
+	}
+}
+
+// channelAncestry returns a channel followed by its parents: a thread yields
+// [thread, parent channel, category], a channel yields [channel, category].
+// Channels missing from the guild index (e.g. archived threads) are looked up
+// individually and cached. ok is false when a lookup failed and the ancestry
+// is incomplete; failed lookups are cached too.
+func (c *DiscordClient) channelAncestry(guildID, channelID string) (ancestry []string, ok bool) {
+	c.indexGuildChannels(guildID)
+
+	id := channelID
+	for depth := 0; id != "" && depth < 3; depth++ {
+		ancestry = append(ancestry, id)
+		parent, known := c.channelParents[id]
+		if !known {
+			if c.unresolvedChannels[id] {
+				return ancestry, false
+			}
+			ch, err := c.GetChannel(id)
+			if err != nil {
+				if !isSafetyHalt(err) {
+					c.unresolvedChannels[id] = true
+				}
+				return ancestry, false
+			}
+			parent = ch.ParentID
+			c.channelParents[id] = parent
+		}
+		id = parent
+	}
+	return ancestry, true
+}
+
+// channelInScope reports whether a channel or thread inside an in-scope guild
+// should be processed, honouring channel, thread and category rules. A
+// channel whose parents can't be looked up is out of scope, since it might
+// sit under an excluded channel or category.
+func (c *DiscordClient) channelInScope(options PurgeOptions, guildID, channelID string) bool {
+	if !options.hasChannelRules(guildID) {
+		return true
+	}
+	ancestry, ok := c.channelAncestry(guildID, channelID)
+	if !ok {
+		if !c.unresolvedReported[channelID] && !c.halted() {
+			c.unresolvedReported[channelID] = true
+			fmt.Fprintf(c.out, "   ⚠️  Could not look up channel %s; skipping it, since your scope rules can't be checked.\n", channelID)
+		}
+		return false
+	}
+	return options.channelAllowed(guildID, ancestry)
+}
+
+// filterGuildChannels drops discovered channels and threads that are out of scope.
+func (c *DiscordClient) filterGuildChannels(options PurgeOptions, guildID string, channelIDs []string) []string {
+	if !options.hasChannelRules(guildID) {
+		return channelIDs
+	}
+	filtered := make([]string, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		if c.channelInScope(options, guildID, channelID) {
+			filtered = append(filtered, channelID)
+		}
+	}
+	if skipped := len(channelIDs) - len(filtered); skipped > 0 {
+		fmt.Fprintf(c.out, "   ↪ Skipping %d channels/threads outside your selected scope.\n", skipped)
+	}
+	return filtered
+}
+
 // =============================================================================
 // Discord Data Package
 // =============================================================================
 
-func LoadDataPackageChannelIDs(packagePath string) ([]string, error) {
+// LoadDataPackageIndex returns the channel ID → name map from the package's
+// messages/index.json. DM names look like "Direct Message with name#0".
+func LoadDataPackageIndex(packagePath string) (map[string]string, error) {
 	indexPath := packagePath
 
 	info, err := os.Stat(packagePath)
@@ -664,39 +977,125 @@ func LoadDataPackageChannelIDs(packagePath string) ([]string, error) {
 		return nil, fmt.Errorf("reading index file: %w", err)
 	}
 
-	var index map[string]interface{}
-	if err := json.Unmarshal(data, &index); err != nil {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("parsing index.json: %w", err)
 	}
 
-	var channelIDs []string
-	for id := range index {
-		channelIDs = append(channelIDs, id)
+	index := make(map[string]string, len(raw))
+	for id, name := range raw {
+		index[id], _ = name.(string)
 	}
 
-	return channelIDs, nil
+	return index, nil
+}
+
+// packageChannelRecipients returns the recipients of a data package channel
+// for DM rules, read from the live channel. ok is false when recipient rules
+// apply but the recipients can't be looked up (e.g. a group DM you left),
+// since the rules can't be checked then.
+func (c *DiscordClient) packageChannelRecipients(options PurgeOptions, channelID string) (recipients []User, ok bool) {
+	if !options.hasRecipientRules() {
+		return nil, true
+	}
+	ch, err := c.GetChannel(channelID)
+	if err != nil || len(ch.Recipients) == 0 {
+		return nil, false
+	}
+	return ch.Recipients, true
 }
 
 // =============================================================================
 // Search and delete methods
 // =============================================================================
 
+// targetAuthor returns the author whose messages are deleted: the account
+// itself, or the target user in moderator mode.
+func (c *DiscordClient) targetAuthor(options PurgeOptions) string {
+	if options.TargetAuthorID != "" {
+		return options.TargetAuthorID
+	}
+	return c.userID
+}
+
+// guildSearchPath builds a search for an author's messages in a guild,
+// newest first, between the optional snowflake bounds.
+func (c *DiscordClient) guildSearchPath(guildID, authorID, maxID, minID string) string {
+	path := fmt.Sprintf("/guilds/%s/messages/search?author_id=%s&include_nsfw=true&sort_by=timestamp&sort_order=desc", guildID, authorID)
+	return path + searchBoundsQuery(maxID, minID)
+}
+
+// dmSearchPath builds the same search for a DM or group DM channel.
+func (c *DiscordClient) dmSearchPath(channelID, maxID, minID string) string {
+	path := fmt.Sprintf("/channels/%s/messages/search?author_id=%s&sort_by=timestamp&sort_order=desc", channelID, c.userID)
+	return path + searchBoundsQuery(maxID, minID)
+}
+
+func searchBoundsQuery(maxID, minID string) string {
+	query := ""
+	if maxID != "" {
+		query += "&max_id=" + maxID
+	}
+	if minID != "" {
+		query += "&min_id=" + minID
+	}
+	return query
+}
+
+// searchTotal runs a search only to read its total_results. It returns -1
+// when the total is unavailable (no access, index still building).
+func (c *DiscordClient) searchTotal(path string) int {
+	for attempt := 0; attempt < 3; attempt++ {
+		body, status, err := c.request("GET", path)
+		if err != nil {
+			return -1
+		}
+		if status == 202 {
+			time.Sleep(3 * time.Second)
+			continue
+		}
+		if status != 200 {
+			return -1
+		}
+
+		var result SearchResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return -1
+		}
+		if result.Retry {
+			time.Sleep(3 * time.Second)
+			continue
+		}
+		return result.TotalResults
+	}
+	return -1
+}
+
 // SearchGuildMessages uses Discord's search API to find all messages by the
-// user in a guild. Covers all text channels, threads, forums, announcements,
-// and voice text chat.
-func (c *DiscordClient) SearchGuildMessages(guildID string) (int, error) {
-	totalDeleted := 0
+// user (or the moderator mode target) in a guild. Covers all text channels,
+// threads, forums, announcements, and voice text chat. Recent messages in
+// channels where the account can manage messages are bulk-deleted.
+func (c *DiscordClient) SearchGuildMessages(guildID string, options PurgeOptions) (totalDeleted int, err error) {
+	authorID := c.targetAuthor(options)
 	indexWaitCount := 0
-	maxID := ""
+	maxID, minID := options.searchBounds()
 	skippedMessageIDs := make(map[string]bool)
-
-	for {
-		path := fmt.Sprintf("/guilds/%s/messages/search?author_id=%s&include_nsfw=true&sort_by=timestamp&sort_order=desc", guildID, c.userID)
-		if maxID != "" {
-			path += "&max_id=" + maxID
+	preserved := 0
+	retention := newRetentionTracker(options.retentionFor(guildID))
+	maxID = retention.rule.searchMaxID(maxID)
+
+	// Queued bulk deletes are sent on every return path.
+	bulk := c.newBulkDeleter(guildID)
+	defer func() {
+		flushed, flushErr := bulk.flush()
+		totalDeleted += flushed
+		if err == nil {
+			err = flushErr
 		}
+	}()
 
-		body, status, err := c.request("GET", path)
+	for !c.controls.skipping() {
+		body, status, err := c.request("GET", c.guildSearchPath(guildID, authorID, maxID, minID))
 		if err != nil {
 			return totalDeleted, fmt.Errorf("search request: %w", err)
 		}
@@ -706,14 +1105,14 @@ func (c *DiscordClient) SearchGuildMessages(guildID string) (int, error) {
 			if indexWaitCount >= maxSearchIndexWaits {
 				return totalDeleted, fmt.Errorf("search index not ready after %d retries", maxSearchIndexWaits)
 			}
-			fmt.Printf("   ⏳ Search index building, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
+			fmt.Fprintf(c.out, "   ⏳ Search index building, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
 			time.Sleep(3 * time.Second)
 			continue
 		}
 		indexWaitCount = 0
 
 		if status == 403 {
-			fmt.Printf("   ⚠️  No permission to search this server, skipping.\n")
+			fmt.Fprintf(c.out, "   ⚠️  No permission to search this server, skipping.\n")
 			return totalDeleted, nil
 		}
 
@@ -730,7 +1129,7 @@ func (c *DiscordClient) SearchGuildMessages(guildID string) (int, error) {
 			if indexWaitCount >= maxSearchIndexWaits {
 				return totalDeleted, fmt.Errorf("search index requested retry too many times")
 			}
-			fmt.Printf("   ⏳ Search requested retry, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
+			fmt.Fprintf(c.out, "   ⏳ Search requested retry, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
 			time.Sleep(3 * time.Second)
 			continue
 		}
@@ -740,7 +1139,7 @@ func (c *DiscordClient) SearchGuildMessages(guildID string) (int, error) {
 			break
 		}
 
-		fmt.Printf("   📊 %d messages remaining...\n", result.TotalResults)
+		fmt.Fprintf(c.out, "   📊 %d messages remaining...\n", result.TotalResults)
 
 		deletedThisRound := 0
 		oldestHitID := ""
@@ -748,52 +1147,90 @@ func (c *DiscordClient) SearchGuildMessages(guildID string) (int, error) {
 
 		for _, msgGroup := range result.Messages {
 			for _, msg := range msgGroup {
-				if msg.Author.ID == c.userID && msg.Hit {
+				if c.controls.skipping() {
+					break
+				}
+				if msg.Author.ID == authorID && msg.Hit {
 					oldestHitID = olderSnowflakeID(oldestHitID, msg.ID)
 
 					if msg.ID == "" || seenInThisPage[msg.ID] || skippedMessageIDs[msg.ID] {
 						continue
 					}
+					if !options.messageInRange(msg.ID) {
+						skippedMessageIDs[msg.ID] = true
+						continue
+					}
 					seenInThisPage[msg.ID] = true
 
-					if msg.ChannelID == "" {
+					if msg.ChannelID == "" || !c.channelInScope(options, guildID, msg.ChannelID) {
+						skippedMessageIDs[msg.ID] = true
+						continue
+					}
+					// Checked after the scope, so messages outside it don't
+					// count as preserved (or suppress the deep scan).
+					if options.Preserve.keeps(msg) {
+						skippedMessageIDs[msg.ID] = true
+						preserved++
+						continue
+					}
+					if retention.keeps(msg.ChannelID, msg.ID) {
+						skippedMessageIDs[msg.ID] = true
+						continue
+					}
+					if options.DryRun {
+						skippedMessageIDs[msg.ID] = true
+						totalDeleted++
+						deletedThisRound++
+						c.progress.messageDeleted()
+						continue
+					}
+					if bulk.accepts(msg.ChannelID, msg.ID) {
 						skippedMessageIDs[msg.ID] = true
+						deletedThisRound++
+						count, err := bulk.add(msg.ChannelID, msg.ID)
+						totalDeleted += count
+						if err != nil {
+							return totalDeleted, err
+						}
 						continue
 					}
 
 					delBody, delStatus, err := c.request("DELETE", fmt.Sprintf("/channels/%s/messages/%s", msg.ChannelID, msg.ID))
-					if err != nil {
-						fmt.Printf("   ⚠️  Failed to delete message %s: %v\n", msg.ID, err)
+					if isSafetyHalt(err) {
+						return totalDeleted, err
+					} else if err != nil {
+						fmt.Fprintf(c.out, "   ⚠️  Failed to delete message %s: %v\n", msg.ID, err)
 						time.Sleep(errorBackoffDelay)
 					} else if delStatus == 204 || delStatus == 200 {
 						totalDeleted++
 						deletedThisRound++
+						c.progress.messageDeleted()
 					} else if delStatus == 404 {
 						deletedThisRound++
 						skippedMessageIDs[msg.ID] = true
 					} else if delStatus == 403 {
-						fmt.Printf("   ⚠️  Cannot delete message %s (no permission)\n", msg.ID)
+						fmt.Fprintf(c.out, "   ⚠️  Cannot delete message %s (no permission)\n", msg.ID)
 						skippedMessageIDs[msg.ID] = true
 					} else if delStatus == 400 {
 						detail := formatAPIError(delBody)
 						if detail != "" {
-							fmt.Printf("   ⚠️  Cannot delete message %s (HTTP 400, %s)\n", msg.ID, detail)
+							fmt.Fprintf(c.out, "   ⚠️  Cannot delete message %s (HTTP 400, %s)\n", msg.ID, detail)
 						} else {
-							fmt.Printf("   ⚠️  Cannot delete message %s (HTTP 400)\n", msg.ID)
+							fmt.Fprintf(c.out, "   ⚠️  Cannot delete message %s (HTTP 400)\n", msg.ID)
 						}
 						skippedMessageIDs[msg.ID] = true
 						time.Sleep(errorBackoffDelay)
 					} else {
 						detail := formatAPIError(delBody)
 						if detail != "" {
-							fmt.Printf("   ⚠️  Unexpected status %d deleting message %s (%s)\n", delStatus, msg.ID, detail)
+							fmt.Fprintf(c.out, "   ⚠️  Unexpected status %d deleting message %s (%s)\n", delStatus, msg.ID, detail)
 						} else {
-							fmt.Printf("   ⚠️  Unexpected status %d deleting message %s\n", delStatus, msg.ID)
+							fmt.Fprintf(c.out, "   ⚠️  Unexpected status %d deleting message %s\n", delStatus, msg.ID)
 						}
 						time.Sleep(errorBackoffDelay)
 					}
 
-					time.Sleep(deleteDelay)
+					c.pacer.Wait(paceDelete)
 				}
 			}
 		}
@@ -809,44 +1246,59 @@ func (c *DiscordClient) SearchGuildMessages(guildID string) (int, error) {
 		maxID = nextMaxID
 
 		if deletedThisRound == 0 {
-			fmt.Printf("   ⚠️  No deletions in this page; continuing deeper into older history.\n")
+			fmt.Fprintf(c.out, "   ⚠️  No deletions in this page; continuing deeper into older history.\n")
 		}
 
-		time.Sleep(searchDelay)
+		c.pacer.Wait(paceSearch)
+	}
+
+	flushed, err := bulk.flush()
+	totalDeleted += flushed
+	if err != nil {
+		return totalDeleted, err
 	}
+	bulk.printSummary()
+	c.printPreserved(preserved)
+	c.printRetained(retention.kept)
 
 	// Discord search can occasionally miss old indexed content. If a guild-level
 	// search found nothing, do an exhaustive channel-by-channel history walk.
-	if totalDeleted == 0 {
-		totalDeleted += c.deepScanGuildMessages(guildID)
+	// A search bounded by After (e.g. a daemon cycle) often finds nothing
+	// simply because the window is quiet, and the walk would read the whole
+	// history every time, so it is skipped then.
+	if totalDeleted == 0 && preserved == 0 && retention.kept == 0 && options.After.IsZero() && !c.halted() && !c.controls.skipping() {
+		totalDeleted += c.deepScanGuildMessages(guildID, options)
 	}
 
 	return totalDeleted, nil
 }
 
-func (c *DiscordClient) deepScanGuildMessages(guildID string) int {
-	channelIDs := c.discoverAllGuildChannelsAndThreads(guildID)
+func (c *DiscordClient) deepScanGuildMessages(guildID string, options PurgeOptions) int {
+	channelIDs := c.filterGuildChannels(options, guildID, c.discoverAllGuildChannelsAndThreads(guildID))
 	if len(channelIDs) == 0 {
 		return 0
 	}
 
-	fmt.Printf("   🔁 Running exhaustive channel scan (%d channels/threads)...\n", len(channelIDs))
+	fmt.Fprintf(c.out, "   🔁 Running exhaustive channel scan (%d channels/threads)...\n", len(channelIDs))
 
 	totalDeleted := 0
 	for i, chID := range channelIDs {
-		count, err := c.iterateAndDeleteChannel(chID)
+		if c.halted() || c.controls.skipping() {
+			break
+		}
+		count, err := c.iterateAndDeleteChannel(guildID, chID, options, nil)
 		if err != nil {
 			continue
 		}
 		totalDeleted += count
 		if count > 0 {
-			fmt.Printf("      ✅ Deleted %d messages in deep scan channel %d/%d\n", count, i+1, len(channelIDs))
+			fmt.Fprintf(c.out, "      ✅ %s %d messages in deep scan channel %d/%d\n", options.deletedVerb(), count, i+1, len(channelIDs))
 		}
-		time.Sleep(batchDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
 	if totalDeleted > 0 {
-		fmt.Printf("   ✅ Deep scan recovered %d additional messages.\n", totalDeleted)
+		fmt.Fprintf(c.out, "   ✅ Deep scan recovered %d additional messages.\n", totalDeleted)
 	}
 
 	return totalDeleted
@@ -854,19 +1306,17 @@ func (c *DiscordClient) deepScanGuildMessages(guildID string) int {
 
 // SearchDMMessages uses Discord's search API to find and delete all messages
 // in a DM or group DM channel.
-func (c *DiscordClient) SearchDMMessages(channelID string) (int, error) {
+func (c *DiscordClient) SearchDMMessages(channelID string, options PurgeOptions) (int, error) {
 	totalDeleted := 0
 	indexWaitCount := 0
-	maxID := ""
+	maxID, minID := options.searchBounds()
 	skippedMessageIDs := make(map[string]bool)
+	preserved := 0
+	retention := newRetentionTracker(options.retentionFor(""))
+	maxID = retention.rule.searchMaxID(maxID)
 
-	for {
-		path := fmt.Sprintf("/channels/%s/messages/search?author_id=%s&sort_by=timestamp&sort_order=desc", channelID, c.userID)
-		if maxID != "" {
-			path += "&max_id=" + maxID
-		}
-
-		body, status, err := c.request("GET", path)
+	for !c.controls.skipping() {
+		body, status, err := c.request("GET", c.dmSearchPath(channelID, maxID, minID))
 		if err != nil {
 			return totalDeleted, fmt.Errorf("search request: %w", err)
 		}
@@ -876,19 +1326,19 @@ func (c *DiscordClient) SearchDMMessages(channelID string) (int, error) {
 			if indexWaitCount >= maxSearchIndexWaits {
 				return totalDeleted, fmt.Errorf("search index not ready after %d retries", maxSearchIndexWaits)
 			}
-			fmt.Printf("   ⏳ Search index building, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
+			fmt.Fprintf(c.out, "   ⏳ Search index building, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
 			time.Sleep(3 * time.Second)
 			continue
 		}
 		indexWaitCount = 0
 
 		if status == 403 || status == 400 || status == 404 {
-			fallbackCount, fallbackErr := c.iterateAndDeleteChannel(channelID)
+			fallbackCount, fallbackErr := c.iterateAndDeleteChannel("", channelID, options, nil)
 			return totalDeleted + fallbackCount, fallbackErr
 		}
 
 		if status != 200 {
-			fallbackCount, fallbackErr := c.iterateAndDeleteChannel(channelID)
+			fallbackCount, fallbackErr := c.iterateAndDeleteChannel("", channelID, options, nil)
 			if fallbackErr != nil {
 				return totalDeleted + fallbackCount, fmt.Errorf("search returned HTTP %d and fallback failed: %w", status, fallbackErr)
 			}
@@ -904,7 +1354,7 @@ func (c *DiscordClient) SearchDMMessages(channelID string) (int, error) {
 			if indexWaitCount >= maxSearchIndexWaits {
 				return totalDeleted, fmt.Errorf("search index requested retry too many times")
 			}
-			fmt.Printf("   ⏳ Search requested retry, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
+			fmt.Fprintf(c.out, "   ⏳ Search requested retry, waiting (%d/%d)...\n", indexWaitCount, maxSearchIndexWaits)
 			time.Sleep(3 * time.Second)
 			continue
 		}
@@ -914,7 +1364,7 @@ func (c *DiscordClient) SearchDMMessages(channelID string) (int, error) {
 			break
 		}
 
-		fmt.Printf("   📊 %d messages remaining...\n", result.TotalResults)
+		fmt.Fprintf(c.out, "   📊 %d messages remaining...\n", result.TotalResults)
 
 		deletedThisRound := 0
 		oldestHitID := ""
@@ -922,47 +1372,73 @@ func (c *DiscordClient) SearchDMMessages(channelID string) (int, error) {
 
 		for _, msgGroup := range result.Messages {
 			for _, msg := range msgGroup {
+				if c.controls.skipping() {
+					break
+				}
 				if msg.Author.ID == c.userID && msg.Hit {
 					oldestHitID = olderSnowflakeID(oldestHitID, msg.ID)
 
 					if msg.ID == "" || seenInThisPage[msg.ID] || skippedMessageIDs[msg.ID] {
 						continue
 					}
+					if !options.messageInRange(msg.ID) {
+						skippedMessageIDs[msg.ID] = true
+						continue
+					}
+					if options.Preserve.keeps(msg) {
+						skippedMessageIDs[msg.ID] = true
+						preserved++
+						continue
+					}
+					if retention.keeps(channelID, msg.ID) {
+						skippedMessageIDs[msg.ID] = true
+						continue
+					}
 					seenInThisPage[msg.ID] = true
+					if options.DryRun {
+						skippedMessageIDs[msg.ID] = true
+						totalDeleted++
+						deletedThisRound++
+						c.progress.messageDeleted()
+						continue
+					}
 
 					delBody, delStatus, err := c.request("DELETE", fmt.Sprintf("/channels/%s/messages/%s", channelID, msg.ID))
-					if err != nil {
-						fmt.Printf("   ⚠️  Failed to delete message %s: %v\n", msg.ID, err)
+					if isSafetyHalt(err) {
+						return totalDeleted, err
+					} else if err != nil {
+						fmt.Fprintf(c.out, "   ⚠️  Failed to delete message %s: %v\n", msg.ID, err)
 						time.Sleep(errorBackoffDelay)
 					} else if delStatus == 204 || delStatus == 200 {
 						totalDeleted++
 						deletedThisRound++
+						c.progress.messageDeleted()
 					} else if delStatus == 404 {
 						deletedThisRound++
 						skippedMessageIDs[msg.ID] = true
 					} else if delStatus == 403 {
 						skippedMessageIDs[msg.ID] = true
-						fmt.Printf("   ⚠️  Cannot delete message %s (no permission)\n", msg.ID)
+						fmt.Fprintf(c.out, "   ⚠️  Cannot delete message %s (no permission)\n", msg.ID)
 					} else if delStatus == 400 {
 						detail := formatAPIError(delBody)
 						if detail != "" {
-							fmt.Printf("   ⚠️  Cannot delete message %s (HTTP 400, %s)\n", msg.ID, detail)
+							fmt.Fprintf(c.out, "   ⚠️  Cannot delete message %s (HTTP 400, %s)\n", msg.ID, detail)
 						} else {
-							fmt.Printf("   ⚠️  Cannot delete message %s (HTTP 400)\n", msg.ID)
+							fmt.Fprintf(c.out, "   ⚠️  Cannot delete message %s (HTTP 400)\n", msg.ID)
 						}
 						skippedMessageIDs[msg.ID] = true
 						time.Sleep(errorBackoffDelay)
 					} else {
 						detail := formatAPIError(delBody)
 						if detail != "" {
-							fmt.Printf("   ⚠️  Unexpected status %d deleting message %s (%s)\n", delStatus, msg.ID, detail)
+							fmt.Fprintf(c.out, "   ⚠️  Unexpected status %d deleting message %s (%s)\n", delStatus, msg.ID, detail)
 						} else {
-							fmt.Printf("   ⚠️  Unexpected status %d deleting message %s\n", delStatus, msg.ID)
+							fmt.Fprintf(c.out, "   ⚠️  Unexpected status %d deleting message %s\n", delStatus, msg.ID)
 						}
 						time.Sleep(errorBackoffDelay)
 					}
 
-					time.Sleep(deleteDelay)
+					c.pacer.Wait(paceDelete)
 				}
 			}
 		}
@@ -978,22 +1454,33 @@ func (c *DiscordClient) SearchDMMessages(channelID string) (int, error) {
 		maxID = nextMaxID
 
 		if deletedThisRound == 0 {
-			fmt.Printf("   ⚠️  No deletions in this page; continuing deeper into older history.\n")
+			fmt.Fprintf(c.out, "   ⚠️  No deletions in this page; continuing deeper into older history.\n")
 		}
 
-		time.Sleep(searchDelay)
+		c.pacer.Wait(paceSearch)
 	}
 
+	c.printPreserved(preserved)
+	c.printRetained(retention.kept)
 	return totalDeleted, nil
 }
 
 // iterateAndDeleteChannel pages through all messages in a channel and deletes
-// the ones authored by the user. Fallback when search API is unavailable.
-func (c *DiscordClient) iterateAndDeleteChannel(channelID string) (int, error) {
+// the ones authored by the user (or the moderator mode target). Fallback when
+// search API is unavailable. guildID selects the retention rule and is empty
+// for DMs. When cursors is non-nil the walk resumes from the channel's cursor
+// and records it after every page.
+func (c *DiscordClient) iterateAndDeleteChannel(guildID, channelID string, options PurgeOptions, cursors map[string]string) (int, error) {
 	totalDeleted := 0
-	beforeID := ""
-
-	for {
+	authorID := c.targetAuthor(options)
+	preserved := 0
+	retention := newRetentionTracker(options.retentionFor(guildID))
+	beforeID, _ := options.searchBounds()
+	beforeID = retention.rule.searchMaxID(beforeID)
+	beforeID = olderSnowflakeID(beforeID, cursors[channelID])
+	bulk := c.newBulkDeleter(guildID)
+
+	for !c.controls.skipping() {
 		path := fmt.Sprintf("/channels/%s/messages?limit=100", channelID)
 		if beforeID != "" {
 			path += "&before=" + beforeID
@@ -1004,8 +1491,8 @@ func (c *DiscordClient) iterateAndDeleteChannel(channelID string) (int, error) {
 			return totalDeleted, fmt.Errorf("fetching messages: %w", err)
 		}
 
-		if status == 403 {
-			break
+		if status == 403 || status == 404 {
+			break // No access or channel gone
 		}
 
 		if status != 200 {
@@ -1022,27 +1509,80 @@ func (c *DiscordClient) iterateAndDeleteChannel(channelID string) (int, error) {
 		}
 
 		for _, msg := range messages {
-			if msg.Author.ID == c.userID {
+			if c.controls.skipping() {
+				break
+			}
+			if msg.Author.ID == authorID && options.messageInRange(msg.ID) {
+				if options.Preserve.keeps(msg) {
+					preserved++
+					continue
+				}
+				if retention.keeps(channelID, msg.ID) {
+					continue
+				}
+				if options.DryRun {
+					totalDeleted++
+					c.progress.messageDeleted()
+					continue
+				}
+				if bulk.accepts(channelID, msg.ID) {
+					count, err := bulk.add(channelID, msg.ID)
+					totalDeleted += count
+					if err != nil {
+						return totalDeleted, err
+					}
+					continue
+				}
 				_, delStatus, err := c.request("DELETE", fmt.Sprintf("/channels/%s/messages/%s", channelID, msg.ID))
+				if isSafetyHalt(err) {
+					return totalDeleted, err
+				}
 				if err == nil && (delStatus == 204 || delStatus == 200 || delStatus == 404) {
 					totalDeleted++
+					c.progress.messageDeleted()
 				}
-				time.Sleep(deleteDelay)
+				c.pacer.Wait(paceDelete)
 			}
 		}
 
+		flushed, err := bulk.flush()
+		totalDeleted += flushed
+		if err != nil {
+			return totalDeleted, err
+		}
+
 		beforeID = messages[len(messages)-1].ID
+		if cursors != nil {
+			cursors[channelID] = beforeID
+		}
 
-		if len(messages) < 100 {
+		if len(messages) < 100 || options.olderThanRange(beforeID) {
 			break
 		}
 
-		time.Sleep(batchDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
+	bulk.printSummary()
+	c.printPreserved(preserved)
+	c.printRetained(retention.kept)
 	return totalDeleted, nil
 }
 
+// printPreserved reports messages kept by preservation rules.
+func (c *DiscordClient) printPreserved(count int) {
+	if count > 0 {
+		fmt.Fprintf(c.out, "   🛡️  Kept %d messages matching your preservation rules\n", count)
+	}
+}
+
+// printRetained reports recent messages kept by retention rules.
+func (c *DiscordClient) printRetained(count int) {
+	if count > 0 {
+		fmt.Fprintf(c.out, "   🗂️  Kept %d recent messages under your retention rules\n", count)
+	}
+}
+
 // =============================================================================
 // Reaction removal methods
 // =============================================================================
@@ -1059,10 +1599,15 @@ func formatEmojiForURL(emoji EmojiInfo) string {
 	return url.PathEscape(emoji.Name)
 }
 
-// removeReaction removes the current user's reaction from a message.
-func (c *DiscordClient) removeReaction(channelID, messageID string, emoji EmojiInfo) error {
+// removeReaction removes the current user's reaction of type reactionType
+// from a message. Super reactions need the type in the path; the plain
+// endpoint only removes normal reactions.
+func (c *DiscordClient) removeReaction(channelID, messageID string, emoji EmojiInfo, reactionType int) error {
 	emojiPath := formatEmojiForURL(emoji)
 	path := fmt.Sprintf("/channels/%s/messages/%s/reactions/%s/@me", channelID, messageID, emojiPath)
+	if reactionType == ReactionTypeBurst {
+		path = fmt.Sprintf("/channels/%s/messages/%s/reactions/%s/%d/@me?burst=true", channelID, messageID, emojiPath, reactionType)
+	}
 
 	_, status, err := c.request("DELETE", path)
 	if err != nil {
@@ -1074,17 +1619,59 @@ func (c *DiscordClient) removeReaction(channelID, messageID string, emoji EmojiI
 	return fmt.Errorf("HTTP %d", status)
 }
 
+// channelScan counts what removeReactionsFromChannel removed in a channel.
+type channelScan struct {
+	Reactions      int
+	SuperReactions int
+	PollVotes      int // polls the vote was withdrawn from
+	ClosedPolls    int // voted polls that are closed, so the vote stays
+}
+
+func (s *channelScan) add(other channelScan) {
+	s.Reactions += other.Reactions
+	s.SuperReactions += other.SuperReactions
+	s.PollVotes += other.PollVotes
+	s.ClosedPolls += other.ClosedPolls
+}
+
+// removed reports whether anything was removed.
+func (s channelScan) removed() bool {
+	return s.Reactions+s.SuperReactions+s.PollVotes > 0
+}
+
+// String describes what was removed.
+func (s channelScan) String() string {
+	parts := []string{fmt.Sprintf("%d reactions", s.Reactions)}
+	if s.SuperReactions > 0 {
+		parts = append(parts, fmt.Sprintf("%d super reactions", s.SuperReactions))
+	}
+	if s.PollVotes > 0 {
+		parts = append(parts, fmt.Sprintf("%d poll votes", s.PollVotes))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // removeReactionsFromChannel iterates through ALL messages in a channel and
-// removes any reactions placed by the current user. Returns the number of
-// reactions removed.
+// removes any reactions placed by the current user, and withdraws the user's
+// votes from open polls. Votes in closed polls can't be changed and are only
+// counted.
 //
 // This must iterate all messages (not just the user's) because reactions can be
 // on anyone's messages. There is no Discord API to search by reactor.
-func (c *DiscordClient) removeReactionsFromChannel(channelID string) int {
-	totalRemoved := 0
+// In a dry run reactions and votes are only counted.
+func (c *DiscordClient) removeReactionsFromChannel(channelID string, options PurgeOptions) channelScan {
+	var scan channelScan
 	beforeID := ""
+	count := func(reactionType int) {
+		if reactionType == ReactionTypeBurst {
+			scan.SuperReactions++
+		} else {
+			scan.Reactions++
+		}
+		c.progress.reactionRemoved()
+	}
 
-	for {
+	for !c.controls.skipping() {
 		path := fmt.Sprintf("/channels/%s/messages?limit=100", channelID)
 		if beforeID != "" {
 			path += "&before=" + beforeID
@@ -1113,16 +1700,49 @@ func (c *DiscordClient) removeReactionsFromChannel(channelID string) int {
 		}
 
 		for _, msg := range messages {
+			if c.controls.skipping() {
+				break
+			}
 			// Check each reaction on this message
 			for _, reaction := range msg.Reactions {
-				if reaction.Me {
-					err := c.removeReaction(channelID, msg.ID, reaction.Emoji)
+				for _, reactionType := range reaction.placedTypes() {
+					if options.DryRun {
+						count(reactionType)
+						continue
+					}
+					err := c.removeReaction(channelID, msg.ID, reaction.Emoji, reactionType)
+					if isSafetyHalt(err) {
+						return scan
+					}
 					if err == nil {
-						totalRemoved++
+						count(reactionType)
 					}
-					time.Sleep(reactionDelay)
+					c.pacer.Wait(paceReaction)
 				}
 			}
+
+			if !msg.Poll.voted() {
+				continue
+			}
+			if msg.Poll.closed(time.Now()) {
+				fmt.Fprintf(c.out, "   🔒 Poll closed, your vote stays: %q\n", msg.Poll.Question.Text)
+				scan.ClosedPolls++
+				continue
+			}
+			if options.DryRun {
+				scan.PollVotes++
+				continue
+			}
+			err := c.RemovePollVote(channelID, msg.ID)
+			if isSafetyHalt(err) {
+				return scan
+			}
+			if err != nil {
+				fmt.Fprintf(c.out, "   ⚠️  Failed to remove your vote from poll %q: %v\n", msg.Poll.Question.Text, err)
+			} else {
+				scan.PollVotes++
+			}
+			c.pacer.Wait(paceReaction)
 		}
 
 		beforeID = messages[len(messages)-1].ID
@@ -1131,10 +1751,10 @@ func (c *DiscordClient) removeReactionsFromChannel(channelID string) int {
 			break
 		}
 
-		time.Sleep(batchDelay)
+		c.pacer.Wait(paceDiscovery)
 	}
 
-	return totalRemoved
+	return scan
 }
 
 // =============================================================================
@@ -1143,29 +1763,248 @@ func (c *DiscordClient) removeReactionsFromChannel(channelID string) int {
 
 // PurgeStats holds detailed statistics about the purge operation
 type PurgeStats struct {
-	TotalMessagesDeleted   int
-	TotalReactionsRemoved  int
-	TotalDMMessagesDeleted int
-	ServerStats            []ServerStat
-	DMChannelsProcessed    int
-	TimeElapsed            time.Duration
+	TotalMessagesDeleted       int                   `json:"total_messages_deleted"`
+	TotalReactionsRemoved      int                   `json:"total_reactions_removed"`
+	TotalSuperReactionsRemoved int                   `json:"total_super_reactions_removed"`
+	TotalPollVotesRemoved      int                   `json:"total_poll_votes_removed"`
+	ClosedPollsKept            int                   `json:"closed_polls_kept"`
+	TotalDMMessagesDeleted     int                   `json:"total_dm_messages_deleted"`
+	ServerStats                []ServerStat          `json:"server_stats"`
+	DMChannelsProcessed        int                   `json:"dm_channels_processed"`
+	TargetAuthorID             string                `json:"target_author_id,omitempty"`
+	DryRun                     bool                  `json:"dry_run,omitempty"`
+	DMHousekeeping             *DMHousekeepingResult `json:"dm_housekeeping,omitempty"`
+	Threads                    *ThreadLeaveResult    `json:"threads,omitempty"`
+	TimeElapsed                time.Duration         `json:"-"`
+	Halted                     bool                  `json:"halted"`
+	HaltReason                 string                `json:"halt_reason,omitempty"`
+
+	// FailedGuilds and FailedDMChannels list the servers and DMs whose
+	// messages couldn't be fully searched; Errors lists the server, DM and
+	// relationship listings that failed. SkippedGuilds and SkippedDMChannels
+	// list the ones skipped on request, which are left out of the checkpoint.
+	FailedGuilds      []string `json:"failed_guilds,omitempty"`
+	FailedDMChannels  []string `json:"failed_dm_channels,omitempty"`
+	Errors            []string `json:"errors,omitempty"`
+	SkippedGuilds     []string `json:"skipped_guilds,omitempty"`
+	SkippedDMChannels []string `json:"skipped_dm_channels,omitempty"`
 }
 
-// ServerStat holds per-server statistics
-type ServerStat struct {
-	GuildID   string
-	GuildName string
-	Messages  int
-	Reactions int
+// failed reports whether part of the scope may not have been searched.
+func (s PurgeStats) failed() bool {
+	return len(s.FailedGuilds) > 0 || len(s.FailedDMChannels) > 0 || len(s.Errors) > 0 ||
+		len(s.SkippedGuilds) > 0 || len(s.SkippedDMChannels) > 0
 }
 
-// PurgeOptions defines optional scope exclusions for the purge operation.
-type PurgeOptions struct {
-	ExcludedGuildIDs     map[string]bool
-	ExcludedDMChannelIDs map[string]bool
+// failureSummary describes what failed, for logs.
+func (s PurgeStats) failureSummary() string {
+	var parts []string
+	if len(s.FailedGuilds) > 0 {
+		parts = append(parts, fmt.Sprintf("%d servers failed", len(s.FailedGuilds)))
+	}
+	if len(s.FailedDMChannels) > 0 {
+		parts = append(parts, fmt.Sprintf("%d DMs failed", len(s.FailedDMChannels)))
+	}
+	if len(s.SkippedGuilds) > 0 {
+		parts = append(parts, fmt.Sprintf("%d servers skipped", len(s.SkippedGuilds)))
+	}
+	if len(s.SkippedDMChannels) > 0 {
+		parts = append(parts, fmt.Sprintf("%d DMs skipped", len(s.SkippedDMChannels)))
+	}
+	parts = append(parts, s.Errors...)
+	return strings.Join(parts, "; ")
 }
 
-func (o PurgeOptions) isGuildExcluded(guildID string) bool {
+// appendUnique appends id to ids unless it is already listed.
+func appendUnique(ids []string, id string) []string {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// ServerStat holds per-server statistics
+type ServerStat struct {
+	GuildID        string   `json:"guild_id"`
+	GuildName      string   `json:"guild_name"`
+	Messages       int      `json:"messages"`
+	Reactions      int      `json:"reactions"`
+	SuperReactions int      `json:"super_reactions,omitempty"`
+	PollVotes      int      `json:"poll_votes,omitempty"`
+	ClosedPolls    int      `json:"closed_polls,omitempty"`
+	IdentityReset  []string `json:"identity_reset,omitempty"`
+	ThreadsLeft    int      `json:"threads_left,omitempty"`
+}
+
+// PreserveRules describes messages that must never be deleted.
+type PreserveRules struct {
+	Pinned         bool     // keep pinned messages
+	MinReactions   int      // keep messages with more than this many reactions in total (0 = off)
+	MinReplies     int      // keep messages whose thread has more than this many replies (0 = off)
+	ReactionEmojis []string // keep messages carrying any of these emoji (unicode or custom name)
+}
+
+// keeps reports whether a message matches any preservation rule.
+func (p PreserveRules) keeps(msg Message) bool {
+	if p.Pinned && msg.Pinned {
+		return true
+	}
+	if p.MinReplies > 0 && msg.Thread != nil && msg.Thread.MessageCount > p.MinReplies {
+		return true
+	}
+	total := 0
+	for _, reaction := range msg.Reactions {
+		total += reaction.Count
+		for _, emoji := range p.ReactionEmojis {
+			if reaction.Emoji.Name == emoji || formatEmojiForURL(reaction.Emoji) == emoji {
+				return true
+			}
+		}
+	}
+	return p.MinReactions > 0 && total > p.MinReactions
+}
+
+// RetentionRule keeps a rolling window of the user's own messages in every
+// channel or DM. When both limits are set a message is kept if either keeps it.
+type RetentionRule struct {
+	KeepLast int // keep the newest N messages per channel (0 = off)
+	KeepDays int // keep messages sent in the last N days (0 = off)
+}
+
+func (r RetentionRule) active() bool {
+	return r.KeepLast > 0 || r.KeepDays > 0
+}
+
+// searchMaxID narrows a search's max_id when only a day window is kept, so
+// searches start below it instead of paging through messages that stay.
+func (r RetentionRule) searchMaxID(maxID string) string {
+	if r.KeepLast > 0 || r.KeepDays <= 0 {
+		return maxID
+	}
+	cutoff := snowflakeFromTime(time.Now().AddDate(0, 0, -r.KeepDays))
+	if maxID == "" {
+		return cutoff
+	}
+	return olderSnowflakeID(maxID, cutoff)
+}
+
+// retentionTracker applies a RetentionRule to messages streamed newest-first,
+// ranking the messages it has seen in each channel.
+type retentionTracker struct {
+	rule   RetentionRule
+	cutoff time.Time
+	ranks  map[string]map[string]int
+	kept   int
+}
+
+func newRetentionTracker(rule RetentionRule) *retentionTracker {
+	t := &retentionTracker{rule: rule, ranks: make(map[string]map[string]int)}
+	if rule.KeepDays > 0 {
+		t.cutoff = time.Now().AddDate(0, 0, -rule.KeepDays)
+	}
+	return t
+}
+
+// keeps reports whether a message falls inside its channel's retention window.
+// Messages must be passed newest-first within each channel.
+func (t *retentionTracker) keeps(channelID, messageID string) bool {
+	if !t.rule.active() {
+		return false
+	}
+	ranks := t.ranks[channelID]
+	if ranks == nil {
+		ranks = make(map[string]int)
+		t.ranks[channelID] = ranks
+	}
+	rank, seen := ranks[messageID]
+	if !seen {
+		rank = len(ranks) + 1
+		ranks[messageID] = rank
+	}
+	keep := (t.rule.KeepLast > 0 && rank <= t.rule.KeepLast) ||
+		(!t.cutoff.IsZero() && snowflakeTime(messageID).After(t.cutoff))
+	if keep && !seen {
+		t.kept++
+	}
+	return keep
+}
+
+// PurgeOptions defines optional scope exclusions for the purge operation.
+type PurgeOptions struct {
+	ExcludedGuildIDs     map[string]bool
+	ExcludedDMChannelIDs map[string]bool
+
+	// ExcludedChannelIDs skips individual server channels and threads (and the
+	// threads of excluded channels); ExcludedCategoryIDs skips every channel
+	// and thread under a category.
+	ExcludedChannelIDs  map[string]bool
+	ExcludedCategoryIDs map[string]bool
+
+	// Rule-based exclusions: guilds whose name matches a pattern, DMs with
+	// recipients listed by user ID or lowercase username, and DMs with users
+	// whose relationship type is listed (e.g. RelationshipFriend).
+	ExcludedGuildNamePatterns []*regexp.Regexp
+	ExcludedDMRecipients      map[string]bool
+	ExcludedRelationshipTypes map[int]bool
+
+	// relationshipTypes maps user IDs to their relationship type; filled in
+	// by loadRelationshipTypes when relationship rules are in use.
+	relationshipTypes map[string]int
+
+	// Include-only scope. When any of these is non-empty, only the listed
+	// servers, DM channels and individual server channels are processed.
+	IncludedGuildIDs     map[string]bool
+	IncludedDMChannelIDs map[string]bool
+	IncludedChannelIDs   map[string]bool
+
+	// includedChannelGuilds maps each included server channel to its guild;
+	// filled in by resolveIncludedChannels.
+	includedChannelGuilds map[string]string
+
+	// Preserve keeps messages that others rely on (pins, popular answers).
+	Preserve PreserveRules
+
+	// Retention keeps a rolling window of recent messages in every channel
+	// and DM; GuildRetention overrides it for individual servers.
+	Retention      RetentionRule
+	GuildRetention map[string]RetentionRule
+
+	// Before and After, when set, limit deletion to messages sent in that range.
+	Before time.Time
+	After  time.Time
+
+	// Phases lists the phases to run ("1", "2a", "2b", "2c", "3", "4"); nil runs all.
+	Phases map[string]bool
+
+	// ReportPath, when set, receives a JSON report at the end of the run.
+	ReportPath string
+
+	// Resume, when set, skips work already completed by an earlier run.
+	Resume *Checkpoint
+
+	// NoEstimate skips the up-front work estimate used for progress and ETA.
+	NoEstimate bool
+
+	// TargetAuthorID, when set, deletes that user's messages instead of the
+	// account's own (moderator mode, Phase 1 only).
+	TargetAuthorID string
+
+	// DryRun counts what would be deleted or removed without changing
+	// anything; checkpoints are neither saved nor cleared.
+	DryRun bool
+
+	// CloseDMs closes every purged DM afterwards, and LeaveGroupDMs silently
+	// leaves every purged group DM.
+	CloseDMs      bool
+	LeaveGroupDMs bool
+
+	// LeaveThreads leaves every joined thread in the purged servers.
+	LeaveThreads bool
+}
+
+func (o PurgeOptions) isGuildExcluded(guildID string) bool {
 	return o.ExcludedGuildIDs != nil && o.ExcludedGuildIDs[guildID]
 }
 
@@ -1173,362 +2012,1150 @@ func (o PurgeOptions) isDMExcluded(channelID string) bool {
 	return o.ExcludedDMChannelIDs != nil && o.ExcludedDMChannelIDs[channelID]
 }
 
+// includeMode reports whether the purge is restricted to an allow-list.
+func (o PurgeOptions) includeMode() bool {
+	return len(o.IncludedGuildIDs) > 0 || len(o.IncludedDMChannelIDs) > 0 || len(o.IncludedChannelIDs) > 0
+}
+
+// guildInScope reports whether any part of a guild should be processed.
+func (o PurgeOptions) guildInScope(guild Guild) bool {
+	if o.isGuildExcluded(guild.ID) {
+		return false
+	}
+	for _, pattern := range o.ExcludedGuildNamePatterns {
+		if pattern.MatchString(guild.Name) {
+			return false
+		}
+	}
+	if !o.includeMode() || o.IncludedGuildIDs[guild.ID] {
+		return true
+	}
+	return len(o.includedChannelsIn(guild.ID)) > 0
+}
+
+// dmInScope reports whether a DM or group DM channel should be processed.
+func (o PurgeOptions) dmInScope(channelID string) bool {
+	if o.isDMExcluded(channelID) {
+		return false
+	}
+	return !o.includeMode() || o.IncludedDMChannelIDs[channelID]
+}
+
+// hasRecipientRules reports whether DM scope depends on who the recipients are.
+func (o PurgeOptions) hasRecipientRules() bool {
+	return len(o.ExcludedDMRecipients) > 0 || len(o.ExcludedRelationshipTypes) > 0
+}
+
+// recipientExcluded reports whether a DM recipient matches a recipient or
+// relationship-type rule.
+func (o PurgeOptions) recipientExcluded(user User) bool {
+	if o.ExcludedDMRecipients[user.ID] || (user.Username != "" && o.ExcludedDMRecipients[strings.ToLower(user.Username)]) {
+		return true
+	}
+	if relType, ok := o.relationshipTypes[user.ID]; ok && o.ExcludedRelationshipTypes[relType] {
+		return true
+	}
+	return false
+}
+
+// dmAllowed applies ID and recipient rules to a DM or group DM channel. A
+// group DM is skipped if any of its recipients matches a rule.
+func (o PurgeOptions) dmAllowed(channelID string, recipients []User) bool {
+	if !o.dmInScope(channelID) {
+		return false
+	}
+	for _, r := range recipients {
+		if o.recipientExcluded(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadRelationshipTypes fetches relationships so relationship-type rules can
+// be applied to DM recipients.
+func (c *DiscordClient) loadRelationshipTypes(options *PurgeOptions) {
+	options.relationshipTypes = make(map[string]int)
+	rels, err := c.GetRelationships()
+	if err != nil {
+		fmt.Fprintf(c.out, "⚠️  Could not load relationships for DM rules: %v\n", err)
+		return
+	}
+	for _, rel := range rels {
+		options.relationshipTypes[rel.User.ID] = rel.Type
+	}
+}
+
+// wholeGuildInScope reports whether every channel of a guild is in scope.
+func (o PurgeOptions) wholeGuildInScope(guildID string) bool {
+	return !o.includeMode() || o.IncludedGuildIDs[guildID]
+}
+
+// hasChannelRules reports whether channel-level scope rules apply within a
+// guild, i.e. whether individual channels need to be checked at all.
+func (o PurgeOptions) hasChannelRules(guildID string) bool {
+	return len(o.ExcludedChannelIDs) > 0 || len(o.ExcludedCategoryIDs) > 0 || !o.wholeGuildInScope(guildID)
+}
+
+// channelAllowed applies channel-level rules to a channel's ancestry (see
+// channelAncestry). Excluding a channel also excludes its threads, and
+// excluding a category excludes every channel and thread beneath it. Whole
+// included guilds (and runs without an allow-list) cover every remaining
+// channel; otherwise a channel counts only if it, its parent channel or its
+// category was included individually.
+func (o PurgeOptions) channelAllowed(guildID string, ancestry []string) bool {
+	for _, id := range ancestry {
+		if o.ExcludedChannelIDs[id] || o.ExcludedCategoryIDs[id] {
+			return false
+		}
+	}
+	if o.wholeGuildInScope(guildID) {
+		return true
+	}
+	for _, id := range ancestry {
+		if o.IncludedChannelIDs[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// includedChannelsIn returns the individually included channels of a guild.
+func (o PurgeOptions) includedChannelsIn(guildID string) []string {
+	var ids []string
+	for channelID, chGuildID := range o.includedChannelGuilds {
+		if chGuildID == guildID {
+			ids = append(ids, channelID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// resolveIncludedChannels looks up the guild of every included channel so
+// guild-level phases know which servers they need to visit.
+func (c *DiscordClient) resolveIncludedChannels(options *PurgeOptions) {
+	options.includedChannelGuilds = make(map[string]string, len(options.IncludedChannelIDs))
+	for channelID := range options.IncludedChannelIDs {
+		ch, err := c.GetChannel(channelID)
+		if err != nil {
+			fmt.Fprintf(c.out, "⚠️  Could not look up included channel %s: %v\n", channelID, err)
+			continue
+		}
+		if ch.GuildID == "" {
+			fmt.Fprintf(c.out, "⚠️  Included channel %s is not a server channel; list DMs as DM channels instead.\n", channelID)
+			continue
+		}
+		options.includedChannelGuilds[channelID] = ch.GuildID
+	}
+}
+
+// retentionFor returns the retention rule for a guild, or for DMs when
+// guildID is empty.
+func (o PurgeOptions) retentionFor(guildID string) RetentionRule {
+	if rule, ok := o.GuildRetention[guildID]; ok && guildID != "" {
+		return rule
+	}
+	return o.Retention
+}
+
+// deletedVerb and removedVerb word per-item results, which only count in a
+// dry run.
+func (o PurgeOptions) deletedVerb() string {
+	if o.DryRun {
+		return "Would delete"
+	}
+	return "Deleted"
+}
+
+func (o PurgeOptions) removedVerb() string {
+	if o.DryRun {
+		return "Would remove"
+	}
+	return "Removed"
+}
+
+func (o PurgeOptions) resetVerb() string {
+	if o.DryRun {
+		return "Would reset"
+	}
+	return "Reset"
+}
+
+func (o PurgeOptions) runsPhase(phase string) bool {
+	return o.Phases == nil || o.Phases[phase]
+}
+
+// searchBounds returns the max_id and min_id matching the date filters.
+func (o PurgeOptions) searchBounds() (maxID, minID string) {
+	if !o.Before.IsZero() {
+		maxID = snowflakeFromTime(o.Before)
+	}
+	if !o.After.IsZero() {
+		minID = snowflakeFromTime(o.After)
+	}
+	return maxID, minID
+}
+
+// messageInRange reports whether a message ID was created within the date filters.
+func (o PurgeOptions) messageInRange(id string) bool {
+	created := snowflakeTime(id)
+	if !o.Before.IsZero() && !created.Before(o.Before) {
+		return false
+	}
+	if !o.After.IsZero() && created.Before(o.After) {
+		return false
+	}
+	return true
+}
+
+// olderThanRange reports whether a message ID predates the After filter, so
+// history walks can stop early.
+func (o PurgeOptions) olderThanRange(id string) bool {
+	return !o.After.IsZero() && snowflakeTime(id).Before(o.After)
+}
+
+// estimateWork measures the run up front: search totals for every server
+// (Phase 1) and open DM (Phase 2a), and the number of channels Phase 3 will
+// scan for reactions. Hidden and data package DMs can't be counted without
+// opening them and are left out. The returned map holds the channels counted
+// per server, so Phase 3 can add the threads it discovers on top (nil when
+// Phase 3 wasn't estimated).
+func (c *DiscordClient) estimateWork(guilds []Guild, options PurgeOptions, checkpoint *Checkpoint) map[string]int {
+	fmt.Fprintln(c.out, "📐 Estimating the amount of work...")
+
+	guildMessages := 0
+	if options.runsPhase("1") {
+		for _, guild := range guilds {
+			if c.halted() {
+				return nil
+			}
+			if checkpoint.CompletedGuilds[guild.ID] {
+				continue
+			}
+			maxID, minID := options.searchBounds()
+			maxID = options.retentionFor(guild.ID).searchMaxID(maxID)
+			if total := c.searchTotal(c.guildSearchPath(guild.ID, c.targetAuthor(options), maxID, minID)); total > 0 {
+				guildMessages += total
+			}
+			c.pacer.Wait(paceSearch)
+		}
+		c.progress.setEstimate("1", guildMessages)
+	}
+
+	var dms []Channel
+	if options.runsPhase("2a") || options.runsPhase("3") {
+		channels, err := c.GetDMChannels()
+		if err == nil {
+			for _, ch := range channels {
+				if options.dmAllowed(ch.ID, ch.Recipients) {
+					dms = append(dms, ch)
+				}
+			}
+		}
+	}
+
+	dmMessages := 0
+	if options.runsPhase("2a") {
+		for _, ch := range dms {
+			if c.halted() {
+				return nil
+			}
+			if checkpoint.CompletedDMChannels[ch.ID] {
+				continue
+			}
+			maxID, minID := options.searchBounds()
+			maxID = options.retentionFor("").searchMaxID(maxID)
+			if total := c.searchTotal(c.dmSearchPath(ch.ID, maxID, minID)); total > 0 {
+				dmMessages += total
+			}
+			c.pacer.Wait(paceSearch)
+		}
+		c.progress.setEstimate("2a", dmMessages)
+	}
+
+	reactionChannels := 0
+	var guildChannels map[string]int
+	if options.runsPhase("3") {
+		guildChannels = make(map[string]int, len(guilds))
+		for _, guild := range guilds {
+			if c.halted() {
+				return nil
+			}
+			if checkpoint.ReactionGuilds[guild.ID] {
+				continue
+			}
+			channels, err := c.GetGuildChannels(guild.ID)
+			if err != nil {
+				continue
+			}
+			var ids []string
+			for _, ch := range channels {
+				switch ch.Type {
+				case ChannelTypeGuildText, ChannelTypeGuildNews, ChannelTypeGuildVoice, ChannelTypeGuildStageVoice,
+					ChannelTypeGuildNewsThread, ChannelTypeGuildPublicThread, ChannelTypeGuildPrivateThread:
+					ids = append(ids, ch.ID)
+				}
+			}
+			guildChannels[guild.ID] = len(c.filterGuildChannels(options, guild.ID, ids))
+			reactionChannels += guildChannels[guild.ID]
+			c.pacer.Wait(paceDiscovery)
+		}
+		reactionChannels += len(dms)
+		c.progress.setEstimate("3", reactionChannels)
+	}
+
+	fmt.Fprintf(c.out, "   ~%d server messages, ~%d open DM messages, %d+ channels to scan for reactions\n", guildMessages, dmMessages, reactionChannels)
+	fmt.Fprintln(c.out)
+	return guildChannels
+}
+
 func (c *DiscordClient) PurgeAll(dataPackagePath string, options PurgeOptions) PurgeStats {
 	totalDeleted := 0
-	totalReactionsRemoved := 0
+	var reactionTotals channelScan
 	totalDMMessages := 0
 	startTime := time.Now()
 
+	// Track what couldn't be searched, so callers know the run was incomplete
+	var failedGuilds, failedDMs, listErrors []string
+	var skippedGuilds, skippedDMs []string
+
 	// Track processed DM channel IDs to avoid duplicate work
 	processedDMs := make(map[string]bool)
 
+	// Track DM channels for the housekeeping after the purge, and how many
+	// DMs re-opened by Phase 2b were closed again
+	knownDMs := make(map[string]Channel)
+	reclosedDMs := 0
+
 	// Track per-server stats
 	var serverStats []ServerStat
 
+	// Track completed work so a halted run can be resumed
+	checkpoint := newCheckpoint(c.userID, options.Resume)
+	checkpoint.TargetAuthorID = options.TargetAuthorID
+	c.progress.start()
+	liveStatus := startLiveStatus(c.progress, c.out)
+	stopControls := c.startControls()
+
+	if !c.halted() {
+		reclosedDMs += c.restoreDMSnapshot()
+	}
+	if len(options.IncludedChannelIDs) > 0 {
+		c.resolveIncludedChannels(&options)
+	}
+	if len(options.ExcludedRelationshipTypes) > 0 {
+		c.loadRelationshipTypes(&options)
+	}
+
+	if options.TargetAuthorID != "" {
+		fmt.Fprintf(c.out, "🛡️  Moderator mode: deleting messages by user %s\n", options.TargetAuthorID)
+	}
+	if options.DryRun {
+		fmt.Fprintln(c.out, "🧪 Dry run: nothing will be deleted; counts show what would be.")
+	}
+	if options.TargetAuthorID != "" || options.DryRun {
+		fmt.Fprintln(c.out)
+	}
+
 	// =========================================================================
 	// Phase 1: Server messages via search API
 	// =========================================================================
-	fmt.Println("📡 Phase 1: Deleting messages from servers (excluding any you skipped)...")
-	fmt.Println()
+	fmt.Fprintln(c.out, "📡 Phase 1: Deleting messages from servers (excluding any you skipped)...")
+	fmt.Fprintln(c.out)
 
+	var estimatedChannels map[string]int
 	guilds, err := c.GetAllGuilds()
 	if err != nil {
-		fmt.Printf("❌ Error fetching servers: %v\n", err)
+		fmt.Fprintf(c.out, "❌ Error fetching servers: %v\n", err)
+		listErrors = append(listErrors, fmt.Sprintf("fetching servers: %v", err))
 		guilds = []Guild{} // Initialize empty slice to avoid nil
 	} else {
 		totalGuildsFound := len(guilds)
 		excludedGuildCount := 0
 
-		if len(options.ExcludedGuildIDs) > 0 {
-			filtered := make([]Guild, 0, len(guilds))
-			for _, guild := range guilds {
-				if options.isGuildExcluded(guild.ID) {
-					excludedGuildCount++
-					continue
-				}
-				filtered = append(filtered, guild)
+		filtered := make([]Guild, 0, len(guilds))
+		for _, guild := range guilds {
+			if !options.guildInScope(guild) {
+				excludedGuildCount++
+				continue
 			}
-			guilds = filtered
+			filtered = append(filtered, guild)
 		}
+		guilds = filtered
 
-		fmt.Printf("✅ Found %d servers.\n", totalGuildsFound)
+		fmt.Fprintf(c.out, "✅ Found %d servers.\n", totalGuildsFound)
 		if excludedGuildCount > 0 {
-			fmt.Printf("   ↪ Excluding %d servers selected by you.\n", excludedGuildCount)
+			fmt.Fprintf(c.out, "   ↪ Skipping %d servers outside your selected scope.\n", excludedGuildCount)
 		}
-		fmt.Println()
+		fmt.Fprintln(c.out)
 
-		for i, guild := range guilds {
-			name := guild.Name
-			if name == "" {
-				name = guild.ID
-			}
-			fmt.Printf("[%d/%d] 🔍 Searching server: %s\n", i+1, len(guilds), name)
+		// Estimates rely on search, which bots can't use.
+		if !options.NoEstimate && !c.bot {
+			estimatedChannels = c.estimateWork(guilds, options, checkpoint)
+		}
 
-			count, err := c.SearchGuildMessages(guild.ID)
-			if err != nil {
-				fmt.Printf("   ❌ Error: %v\n", err)
-			}
-			if count > 0 {
-				fmt.Printf("   ✅ Deleted %d messages\n", count)
-			} else {
-				fmt.Printf("   ✓ No messages found\n")
-			}
-			totalDeleted += count
+		if options.runsPhase("1") {
+			checkpoint.Phase = "1"
+			c.progress.startPhase("1", len(guilds))
+			for i, guild := range guilds {
+				if c.halted() {
+					break
+				}
+				name := guild.Name
+				if name == "" {
+					name = guild.ID
+				}
+				if checkpoint.CompletedGuilds[guild.ID] {
+					fmt.Fprintf(c.out, "[%d/%d] ⏭️  Already completed in a previous run: %s\n", i+1, len(guilds), name)
+					serverStats = append(serverStats, ServerStat{GuildID: guild.ID, GuildName: name})
+					c.progress.itemDone()
+					continue
+				}
+				fmt.Fprintf(c.out, "[%d/%d] 🔍 Searching server: %s\n", i+1, len(guilds), name)
+				c.beginItem(name)
+
+				var count int
+				if c.bot {
+					count, err = c.walkGuildMessages(guild.ID, options, checkpoint)
+				} else {
+					count, err = c.SearchGuildMessages(guild.ID, options)
+				}
+				skipped := c.endItem()
+				if err != nil {
+					fmt.Fprintf(c.out, "   ❌ Error: %v\n", err)
+					if !isSafetyHalt(err) {
+						failedGuilds = append(failedGuilds, guild.ID)
+					}
+				}
+				if count > 0 {
+					fmt.Fprintf(c.out, "   ✅ %s %d messages\n", options.deletedVerb(), count)
+				} else {
+					fmt.Fprintf(c.out, "   ✓ No messages found\n")
+				}
+				totalDeleted += count
 
-			// Initialize server stat (reactions will be added in Phase 3)
-			serverStats = append(serverStats, ServerStat{
-				GuildID:   guild.ID,
-				GuildName: name,
-				Messages:  count,
-				Reactions: 0,
-			})
-			fmt.Println()
+				// Initialize server stat (reactions will be added in Phase 3)
+				serverStats = append(serverStats, ServerStat{
+					GuildID:   guild.ID,
+					GuildName: name,
+					Messages:  count,
+					Reactions: 0,
+				})
+				fmt.Fprintln(c.out)
+
+				if skipped {
+					skippedGuilds = appendUnique(skippedGuilds, guild.ID)
+					c.progress.itemSkipped()
+				} else if !c.halted() {
+					checkpoint.CompletedGuilds[guild.ID] = true
+					c.progress.itemDone()
+				}
+			}
+			c.progress.endPhase("1", phaseDone)
+		} else {
+			fmt.Fprintln(c.out, "⏭️  Phase 1 skipped (not selected in profile).")
+			fmt.Fprintln(c.out)
+			c.progress.endPhase("1", phaseSkipped)
 		}
 	}
 
 	// =========================================================================
 	// Phase 2a: Visible/open DM channels
 	// =========================================================================
-	fmt.Println("💬 Phase 2a: Deleting messages from open/visible DM channels (excluding any you skipped)...")
-	fmt.Println()
+	if !c.halted() && options.runsPhase("2a") {
+		fmt.Fprintln(c.out, "💬 Phase 2a: Deleting messages from open/visible DM channels (excluding any you skipped)...")
+		fmt.Fprintln(c.out)
 
-	channels, err := c.GetDMChannels()
-	if err != nil {
-		fmt.Printf("❌ Error fetching DM channels: %v\n", err)
-	} else {
-		totalOpenDMsFound := len(channels)
-		excludedOpenDMCount := 0
-		channelsToProcess := channels
+		channels, err := c.GetDMChannels()
+		if err != nil {
+			fmt.Fprintf(c.out, "❌ Error fetching DM channels: %v\n", err)
+			listErrors = append(listErrors, fmt.Sprintf("fetching DM channels: %v", err))
+		} else {
+			totalOpenDMsFound := len(channels)
+			excludedOpenDMCount := 0
 
-		if len(options.ExcludedDMChannelIDs) > 0 {
-			channelsToProcess = make([]Channel, 0, len(channels))
+			channelsToProcess := make([]Channel, 0, len(channels))
 			for _, ch := range channels {
-				if options.isDMExcluded(ch.ID) {
+				if !options.dmAllowed(ch.ID, ch.Recipients) {
 					excludedOpenDMCount++
 					continue
 				}
 				channelsToProcess = append(channelsToProcess, ch)
 			}
-		}
 
-		fmt.Printf("✅ Found %d open DM channels.\n", totalOpenDMsFound)
-		if excludedOpenDMCount > 0 {
-			fmt.Printf("   ↪ Excluding %d DM/group DM channels selected by you.\n", excludedOpenDMCount)
-		}
-		fmt.Println()
+			fmt.Fprintf(c.out, "✅ Found %d open DM channels.\n", totalOpenDMsFound)
+			if excludedOpenDMCount > 0 {
+				fmt.Fprintf(c.out, "   ↪ Skipping %d DM/group DM channels outside your selected scope.\n", excludedOpenDMCount)
+			}
+			fmt.Fprintln(c.out)
 
-		for i, ch := range channelsToProcess {
-			processedDMs[ch.ID] = true
-			label := describeChannel(ch)
-			fmt.Printf("[%d/%d] 🔍 Processing DM: %s\n", i+1, len(channelsToProcess), label)
+			checkpoint.Phase = "2a"
+			c.progress.startPhase("2a", len(channelsToProcess))
+			for i, ch := range channelsToProcess {
+				if c.halted() {
+					break
+				}
+				processedDMs[ch.ID] = true
+				knownDMs[ch.ID] = ch
+				label := describeChannel(ch)
+				if checkpoint.CompletedDMChannels[ch.ID] {
+					fmt.Fprintf(c.out, "[%d/%d] ⏭️  Already completed in a previous run: %s\n", i+1, len(channelsToProcess), label)
+					c.progress.itemDone()
+					continue
+				}
+				fmt.Fprintf(c.out, "[%d/%d] 🔍 Processing DM: %s\n", i+1, len(channelsToProcess), label)
+				c.beginItem(label)
 
-			count, err := c.SearchDMMessages(ch.ID)
-			if err != nil {
-				fmt.Printf("   ❌ Error: %v\n", err)
-			}
-			if count > 0 {
-				fmt.Printf("   ✅ Deleted %d messages\n", count)
-			} else {
-				fmt.Printf("   ✓ No messages found\n")
+				count, err := c.SearchDMMessages(ch.ID, options)
+				skipped := c.endItem()
+				if err != nil {
+					fmt.Fprintf(c.out, "   ❌ Error: %v\n", err)
+					if !isSafetyHalt(err) {
+						failedDMs = append(failedDMs, ch.ID)
+					}
+				}
+				if count > 0 {
+					fmt.Fprintf(c.out, "   ✅ %s %d messages\n", options.deletedVerb(), count)
+				} else {
+					fmt.Fprintf(c.out, "   ✓ No messages found\n")
+				}
+				totalDMMessages += count
+				totalDeleted += count
+				fmt.Fprintln(c.out)
+
+				if skipped {
+					skippedDMs = appendUnique(skippedDMs, ch.ID)
+					c.progress.itemSkipped()
+				} else if !c.halted() {
+					checkpoint.CompletedDMChannels[ch.ID] = true
+					c.progress.itemDone()
+				}
 			}
-			totalDMMessages += count
-			totalDeleted += count
-			fmt.Println()
+			c.progress.endPhase("2a", phaseDone)
 		}
+	} else if !c.halted() {
+		fmt.Fprintln(c.out, "⏭️  Phase 2a skipped (not selected in profile).")
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("2a", phaseSkipped)
 	}
 
 	// =========================================================================
 	// Phase 2b: Hidden DMs via relationships
 	// =========================================================================
-	fmt.Println("🔗 Phase 2b: Discovering hidden/closed DMs via relationships...")
-	fmt.Println("   (Re-opening DMs with friends, blocked users, and pending requests)")
-	fmt.Println()
+	if !c.halted() && options.runsPhase("2b") {
+		fmt.Fprintln(c.out, "🔗 Phase 2b: Discovering hidden/closed DMs via relationships...")
+		if options.DryRun {
+			fmt.Fprintln(c.out, "   (Listing closed DMs with friends, blocked users, and pending requests without re-opening them)")
+		} else {
+			fmt.Fprintln(c.out, "   (Re-opening DMs with friends, blocked users, and pending requests)")
+		}
+		fmt.Fprintln(c.out)
+
+		if options.includeMode() && len(options.IncludedDMChannelIDs) == 0 {
+			// Nothing to find: don't force-open DMs that are out of scope anyway.
+			fmt.Fprintln(c.out, "   ↪ Skipped — your selected scope includes no DM channels.")
+			fmt.Fprintln(c.out)
+			c.progress.endPhase("2b", phaseSkipped)
+		} else if rels, err := c.GetRelationships(); err != nil {
+			fmt.Fprintf(c.out, "❌ Error fetching relationships: %v\n", err)
+			listErrors = append(listErrors, fmt.Sprintf("fetching relationships: %v", err))
+		} else {
+			fmt.Fprintf(c.out, "✅ Found %d relationships.\n", len(rels))
+
+			// DMs that aren't open now are re-opened by the search below and
+			// closed again afterwards; the snapshot on disk lets the next run
+			// close them if this one is interrupted. A dry run opens nothing:
+			// it searches the DMs that are open and lists the closed ones.
+			var snapshot *DMSnapshot
+			openDMs := make(map[string]Channel)
+			open, err := c.GetDMChannels()
+			listClosed := err == nil
+			switch {
+			case err != nil && options.DryRun:
+				fmt.Fprintf(c.out, "⚠️  Could not list open DMs (%v); the dry run can't list closed DMs.\n", err)
+			case err != nil:
+				fmt.Fprintf(c.out, "⚠️  Could not list open DMs (%v); re-opened DMs will stay open.\n", err)
+			case options.DryRun:
+				for _, ch := range open {
+					if ch.Type == ChannelTypeDM && len(ch.Recipients) == 1 {
+						openDMs[ch.Recipients[0].ID] = ch
+					}
+				}
+			default:
+				if snapshot, err = newDMSnapshot(dmSnapshotFile, c.userID, open); err != nil {
+					fmt.Fprintf(c.out, "⚠️  %v; re-opened DMs will stay open.\n", err)
+				}
+			}
 
-	rels, err := c.GetRelationships()
-	if err != nil {
-		fmt.Printf("❌ Error fetching relationships: %v\n", err)
-	} else {
-		fmt.Printf("✅ Found %d relationships.\n", len(rels))
+			discoveredCount := 0
+			closedDMCount := 0
+			excludedHiddenDMCount := 0
+			checkpoint.Phase = "2b"
+			c.progress.startPhase("2b", len(rels))
+			for _, rel := range rels {
+				if c.halted() {
+					break
+				}
+				// Recipient rules are checked first so excluded DMs are never re-opened.
+				if options.recipientExcluded(rel.User) {
+					excludedHiddenDMCount++
+					c.progress.itemDone()
+					continue
+				}
+				var ch *Channel
+				if options.DryRun {
+					dm, ok := openDMs[rel.User.ID]
+					if !ok && listClosed {
+						fmt.Fprintf(c.out, "   🔒 Would re-open the closed DM with %s (%s)\n", rel.User.Username, relationshipLabel(rel.Type))
+						closedDMCount++
+					}
+					if !ok {
+						c.progress.itemDone()
+						continue
+					}
+					ch = &dm
+				} else if ch, err = c.OpenDMChannel(rel.User.ID); err != nil {
+					c.progress.itemDone()
+					continue
+				}
+				knownDMs[ch.ID] = *ch
+				if snapshot != nil {
+					if err := snapshot.recordOpened(ch.ID); err != nil {
+						fmt.Fprintf(c.out, "   ⚠️  %v; re-opened DMs are only closed if this run finishes.\n", err)
+					}
+				}
 
-		discoveredCount := 0
-		excludedHiddenDMCount := 0
-		for _, rel := range rels {
-			ch, err := c.OpenDMChannel(rel.User.ID)
-			if err != nil {
-				continue
-			}
+				if processedDMs[ch.ID] {
+					c.progress.itemDone()
+					continue
+				}
+				if !options.dmAllowed(ch.ID, ch.Recipients) {
+					excludedHiddenDMCount++
+					c.progress.itemDone()
+					continue
+				}
 
-			if processedDMs[ch.ID] {
-				continue
-			}
-			if options.isDMExcluded(ch.ID) {
-				excludedHiddenDMCount++
-				continue
-			}
+				discoveredCount++
+				processedDMs[ch.ID] = true
+				if checkpoint.CompletedDMChannels[ch.ID] {
+					c.progress.itemDone()
+					continue
+				}
 
-			discoveredCount++
-			processedDMs[ch.ID] = true
+				fmt.Fprintf(c.out, "   🔓 Found hidden DM with %s (%s)\n", rel.User.Username, relationshipLabel(rel.Type))
+				c.beginItem(rel.User.Username)
 
-			relType := "related"
-			switch rel.Type {
-			case RelationshipFriend:
-				relType = "friend"
-			case RelationshipBlocked:
-				relType = "blocked"
-			case RelationshipIncomingReq:
-				relType = "incoming request"
-			case RelationshipOutgoingReq:
-				relType = "outgoing request"
-			}
+				count, err := c.SearchDMMessages(ch.ID, options)
+				skipped := c.endItem()
+				if err != nil {
+					fmt.Fprintf(c.out, "      ❌ Error: %v\n", err)
+					if !isSafetyHalt(err) {
+						failedDMs = append(failedDMs, ch.ID)
+					}
+				}
+				if count > 0 {
+					fmt.Fprintf(c.out, "      ✅ %s %d messages\n", options.deletedVerb(), count)
+				}
+				totalDMMessages += count
+				totalDeleted += count
 
-			fmt.Printf("   🔓 Found hidden DM with %s (%s)\n", rel.User.Username, relType)
+				if skipped {
+					skippedDMs = appendUnique(skippedDMs, ch.ID)
+					c.progress.itemSkipped()
+				} else if !c.halted() {
+					checkpoint.CompletedDMChannels[ch.ID] = true
+					c.progress.itemDone()
+				}
 
-			count, err := c.SearchDMMessages(ch.ID)
-			if err != nil {
-				fmt.Printf("      ❌ Error: %v\n", err)
+				time.Sleep(500 * time.Millisecond)
 			}
-			if count > 0 {
-				fmt.Printf("      ✅ Deleted %d messages\n", count)
-			}
-			totalDMMessages += count
-			totalDeleted += count
+			c.progress.endPhase("2b", phaseDone)
 
-			time.Sleep(500 * time.Millisecond)
-		}
-
-		if discoveredCount == 0 {
-			fmt.Println("   ✓ No additional hidden DMs found (all already processed)")
-		}
-		if excludedHiddenDMCount > 0 {
-			fmt.Printf("   ↪ Skipped %d hidden DM channels from your exclusion list.\n", excludedHiddenDMCount)
+			if snapshot != nil && len(snapshot.Reopened) > 0 {
+				count := c.recloseDMs(snapshot)
+				reclosedDMs += count
+				if len(snapshot.Reopened) > 0 {
+					fmt.Fprintf(c.out, "   💾 %d re-opened DMs are listed in %s and will be closed by the next run.\n", len(snapshot.Reopened), dmSnapshotFile)
+				}
+				if count > 0 {
+					fmt.Fprintf(c.out, "   🧹 Closed again %d DMs this phase re-opened\n", count)
+				}
+			}
+			if closedDMCount > 0 {
+				fmt.Fprintf(c.out, "   🧪 %d closed DMs would be re-opened and searched; a dry run can't look inside them.\n", closedDMCount)
+			} else if discoveredCount == 0 {
+				fmt.Fprintln(c.out, "   ✓ No additional hidden DMs found (all already processed)")
+			}
+			if excludedHiddenDMCount > 0 {
+				fmt.Fprintf(c.out, "   ↪ Skipped %d hidden DM channels outside your selected scope.\n", excludedHiddenDMCount)
+			}
+			fmt.Fprintln(c.out)
 		}
-		fmt.Println()
+	} else if !c.halted() {
+		fmt.Fprintln(c.out, "⏭️  Phase 2b skipped (not selected in profile).")
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("2b", phaseSkipped)
 	}
 
 	// =========================================================================
 	// Phase 2c: DMs from Discord data package (optional)
 	// =========================================================================
-	if dataPackagePath != "" {
-		fmt.Println("📦 Phase 2c: Processing DMs from Discord data package...")
-		fmt.Printf("   Loading: %s\n", dataPackagePath)
+	if !c.halted() && options.runsPhase("2c") {
+		if dataPackagePath != "" {
+			fmt.Fprintln(c.out, "📦 Phase 2c: Processing DMs from Discord data package...")
+			fmt.Fprintf(c.out, "   Loading: %s\n", dataPackagePath)
 
-		packageChannelIDs, err := LoadDataPackageChannelIDs(dataPackagePath)
-		if err != nil {
-			fmt.Printf("❌ Error loading data package: %v\n", err)
-		} else {
-			fmt.Printf("✅ Found %d channels in data package.\n", len(packageChannelIDs))
-
-			newChannels := 0
-			excludedPackageChannelCount := 0
-			for _, chID := range packageChannelIDs {
-				if options.isDMExcluded(chID) {
-					excludedPackageChannelCount++
-					continue
-				}
-				if processedDMs[chID] {
-					continue
+			packageIndex, err := LoadDataPackageIndex(dataPackagePath)
+			if err != nil {
+				fmt.Fprintf(c.out, "❌ Error loading data package: %v\n", err)
+				listErrors = append(listErrors, fmt.Sprintf("loading data package: %v", err))
+			} else {
+				packageChannelIDs := make([]string, 0, len(packageIndex))
+				for id := range packageIndex {
+					packageChannelIDs = append(packageChannelIDs, id)
 				}
-				processedDMs[chID] = true
-				newChannels++
+				fmt.Fprintf(c.out, "✅ Found %d channels in data package.\n", len(packageChannelIDs))
+
+				newChannels := 0
+				excludedPackageChannelCount := 0
+				checkpoint.Phase = "2c"
+				c.progress.startPhase("2c", len(packageChannelIDs))
+				for _, chID := range packageChannelIDs {
+					if c.halted() {
+						break
+					}
+					if processedDMs[chID] {
+						c.progress.itemDone()
+						continue
+					}
+					recipients, resolved := c.packageChannelRecipients(options, chID)
+					if !resolved && !c.halted() {
+						name := chID
+						if packageIndex[chID] != "" {
+							name = fmt.Sprintf("%s (%s)", packageIndex[chID], chID)
+						}
+						fmt.Fprintf(c.out, "   ⚠️  Could not look up the recipients of %s; skipping it, since your DM rules can't be checked.\n", name)
+					}
+					if !resolved || !options.dmAllowed(chID, recipients) {
+						excludedPackageChannelCount++
+						c.progress.itemDone()
+						continue
+					}
+					processedDMs[chID] = true
+					newChannels++
+					if checkpoint.CompletedDMChannels[chID] {
+						c.progress.itemDone()
+						continue
+					}
 
-				fmt.Printf("   🔍 Processing data package channel: %s\n", chID)
+					fmt.Fprintf(c.out, "   🔍 Processing data package channel: %s\n", chID)
+					c.beginItem(chID)
 
-				count, err := c.SearchDMMessages(chID)
-				if err != nil {
-					count, _ = c.iterateAndDeleteChannel(chID)
-				}
-				if count > 0 {
-					fmt.Printf("      ✅ Deleted %d messages\n", count)
+					count, err := c.SearchDMMessages(chID, options)
+					if err != nil && !isSafetyHalt(err) && !c.controls.skipping() {
+						count, err = c.iterateAndDeleteChannel("", chID, options, nil)
+					}
+					skipped := c.endItem()
+					if err != nil && !isSafetyHalt(err) {
+						fmt.Fprintf(c.out, "      ❌ Error: %v\n", err)
+						failedDMs = append(failedDMs, chID)
+					}
+					if count > 0 {
+						fmt.Fprintf(c.out, "      ✅ %s %d messages\n", options.deletedVerb(), count)
+					}
+					totalDMMessages += count
+					totalDeleted += count
+
+					if skipped {
+						skippedDMs = appendUnique(skippedDMs, chID)
+						c.progress.itemSkipped()
+					} else if !c.halted() {
+						checkpoint.CompletedDMChannels[chID] = true
+						c.progress.itemDone()
+					}
 				}
-				totalDMMessages += count
-				totalDeleted += count
-			}
+				c.progress.endPhase("2c", phaseDone)
 
-			if newChannels == 0 {
-				fmt.Println("   ✓ No additional channels found beyond what was already processed")
-			}
-			if excludedPackageChannelCount > 0 {
-				fmt.Printf("   ↪ Skipped %d data package channels from your exclusion list.\n", excludedPackageChannelCount)
+				if newChannels == 0 {
+					fmt.Fprintln(c.out, "   ✓ No additional channels found beyond what was already processed")
+				}
+				if excludedPackageChannelCount > 0 {
+					fmt.Fprintf(c.out, "   ↪ Skipped %d data package channels outside your selected scope.\n", excludedPackageChannelCount)
+				}
+				fmt.Fprintln(c.out)
 			}
-			fmt.Println()
+		} else {
+			fmt.Fprintln(c.out, "📦 Phase 2c: Discord data package (skipped — not provided)")
+			fmt.Fprintln(c.out, "   For the most complete DM coverage, provide your Discord data export:")
+			fmt.Fprintln(c.out, "   discord-purge --data-package /path/to/package")
+			fmt.Fprintln(c.out)
+			c.progress.endPhase("2c", phaseSkipped)
 		}
-	} else {
-		fmt.Println("📦 Phase 2c: Discord data package (skipped — not provided)")
-		fmt.Println("   For the most complete DM coverage, provide your Discord data export:")
-		fmt.Println("   discord-purge --data-package /path/to/package")
-		fmt.Println()
+	} else if !c.halted() {
+		fmt.Fprintln(c.out, "⏭️  Phase 2c skipped (not selected in profile).")
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("2c", phaseSkipped)
 	}
 
 	// =========================================================================
 	// Phase 3: Remove all reactions from server channels
 	// =========================================================================
-	fmt.Println("👎 Phase 3: Removing reactions you placed on other people's messages...")
-	fmt.Println("   (This requires scanning all messages in all channels — may take a while)")
-	fmt.Println()
+	if !c.halted() && options.runsPhase("3") {
+		fmt.Fprintln(c.out, "👎 Phase 3: Removing reactions you placed on other people's messages...")
+		fmt.Fprintln(c.out, "   (This requires scanning all messages in all channels — may take a while)")
+		fmt.Fprintln(c.out)
+
+		// Phase 3a: Server reactions
+		checkpoint.Phase = "3a"
+		c.progress.startPhase("3", len(guilds)+len(processedDMs))
+		for i, guild := range guilds {
+			if c.halted() {
+				break
+			}
+			name := guild.Name
+			if name == "" {
+				name = guild.ID
+			}
+			if checkpoint.ReactionGuilds[guild.ID] {
+				fmt.Fprintf(c.out, "[%d/%d] ⏭️  Reactions already scanned in a previous run: %s\n", i+1, len(guilds), name)
+				c.progress.itemDone()
+				continue
+			}
+			fmt.Fprintf(c.out, "[%d/%d] 🔍 Scanning server for reactions: %s\n", i+1, len(guilds), name)
+			c.beginItem(name)
+
+			// Discover all text channels + threads in this guild
+			channelIDs := c.filterGuildChannels(options, guild.ID, c.discoverAllGuildChannelsAndThreads(guild.ID))
+			fmt.Fprintf(c.out, "   📂 Found %d channels/threads to scan\n", len(channelIDs))
+			if estimatedChannels != nil && len(channelIDs) > estimatedChannels[guild.ID] {
+				// The estimate only saw the channel list; add the threads found now.
+				c.progress.addEstimate("3", len(channelIDs)-estimatedChannels[guild.ID])
+			}
 
-	// Phase 3a: Server reactions
-	for i, guild := range guilds {
-		name := guild.Name
-		if name == "" {
-			name = guild.ID
+			var guildScan channelScan
+			for j, chID := range channelIDs {
+				if c.halted() || c.controls.skipping() {
+					break
+				}
+				scan := c.removeReactionsFromChannel(chID, options)
+				c.progress.channelScanned()
+				guildScan.add(scan)
+				if scan.removed() {
+					fmt.Fprintf(c.out, "   ✅ %s %s from channel %d/%d\n", options.removedVerb(), scan, j+1, len(channelIDs))
+				}
+			}
+			skipped := c.endItem()
+
+			// Update server stats with reaction and poll counts
+			stat := serverStat(&serverStats, guild.ID, name)
+			stat.Reactions = guildScan.Reactions
+			stat.SuperReactions = guildScan.SuperReactions
+			stat.PollVotes = guildScan.PollVotes
+			stat.ClosedPolls = guildScan.ClosedPolls
+
+			reactionTotals.add(guildScan)
+			if guildScan.removed() {
+				fmt.Fprintf(c.out, "   ✅ Total: %s %s from this server\n", strings.ToLower(options.removedVerb()), guildScan)
+			} else {
+				fmt.Fprintf(c.out, "   ✓ No reactions found\n")
+			}
+			fmt.Fprintln(c.out)
+
+			if skipped {
+				skippedGuilds = appendUnique(skippedGuilds, guild.ID)
+				c.progress.itemSkipped()
+			} else if !c.halted() {
+				checkpoint.ReactionGuilds[guild.ID] = true
+				c.progress.itemDone()
+			}
 		}
-		fmt.Printf("[%d/%d] 🔍 Scanning server for reactions: %s\n", i+1, len(guilds), name)
-
-		// Discover all text channels + threads in this guild
-		channelIDs := c.discoverAllGuildChannelsAndThreads(guild.ID)
-		fmt.Printf("   📂 Found %d channels/threads to scan\n", len(channelIDs))
 
-		guildReactions := 0
-		for j, chID := range channelIDs {
-			removed := c.removeReactionsFromChannel(chID)
-			guildReactions += removed
-			if removed > 0 {
-				fmt.Printf("   ✅ Removed %d reactions from channel %d/%d\n", removed, j+1, len(channelIDs))
+		// Phase 3b: DM reactions
+		fmt.Fprintln(c.out, "   💬 Scanning DM channels for reactions...")
+		var dmScan channelScan
+		checkpoint.Phase = "3b"
+		for chID := range processedDMs {
+			if c.halted() {
+				break
+			}
+			if checkpoint.ReactionDMChannels[chID] {
+				c.progress.itemDone()
+				continue
 			}
+			c.beginItem(chID)
+			scan := c.removeReactionsFromChannel(chID, options)
+			c.progress.channelScanned()
+			skipped := c.endItem()
+			dmScan.add(scan)
+			if scan.removed() {
+				fmt.Fprintf(c.out, "   ✅ %s %s from DM %s\n", options.removedVerb(), scan, chID)
+			}
+			if skipped {
+				skippedDMs = appendUnique(skippedDMs, chID)
+				c.progress.itemSkipped()
+			} else if !c.halted() {
+				checkpoint.ReactionDMChannels[chID] = true
+				c.progress.itemDone()
+			}
+		}
+		reactionTotals.add(dmScan)
+
+		if !dmScan.removed() {
+			fmt.Fprintln(c.out, "   ✓ No DM reactions found")
 		}
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("3", phaseDone)
+	} else if !c.halted() {
+		fmt.Fprintln(c.out, "⏭️  Phase 3 skipped (not selected in profile).")
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("3", phaseSkipped)
+	}
+
+	// =========================================================================
+	// Phase 4: Per-server nicknames, avatars, banners and bios
+	// =========================================================================
+	if !c.halted() && options.runsPhase("4") {
+		fmt.Fprintln(c.out, "🪪 Phase 4: Resetting your nickname and profile in each server...")
+		fmt.Fprintln(c.out)
 
-		// Update server stats with reaction count
-		for i := range serverStats {
-			if serverStats[i].GuildID == guild.ID {
-				serverStats[i].Reactions = guildReactions
+		checkpoint.Phase = "4"
+		c.progress.startPhase("4", len(guilds))
+		identitiesReset := 0
+		for i, guild := range guilds {
+			if c.halted() {
+				break
+			}
+			name := guild.Name
+			if name == "" {
+				name = guild.ID
+			}
+			// A server that is only partly in scope keeps its profile.
+			if !options.wholeGuildInScope(guild.ID) {
+				c.progress.itemDone()
+				continue
+			}
+			c.beginItem(name)
+			fields, err := c.resetServerIdentity(guild.ID, options)
+			c.endItem()
+			c.pacer.Wait(paceDiscovery)
+			if isSafetyHalt(err) {
 				break
 			}
+			if err != nil {
+				fmt.Fprintf(c.out, "[%d/%d] ⚠️  %s: %v\n", i+1, len(guilds), name, err)
+			} else if len(fields) > 0 {
+				fmt.Fprintf(c.out, "[%d/%d] ✅ %s: %s %s\n", i+1, len(guilds), name, strings.ToLower(options.resetVerb()), strings.Join(fields, ", "))
+				serverStat(&serverStats, guild.ID, name).IdentityReset = fields
+				identitiesReset++
+				c.progress.identityReset()
+			}
+			c.progress.itemDone()
 		}
 
-		totalReactionsRemoved += guildReactions
-		if guildReactions > 0 {
-			fmt.Printf("   ✅ Total: removed %d reactions from this server\n", guildReactions)
+		if identitiesReset > 0 {
+			fmt.Fprintf(c.out, "   ✅ %s your profile in %d servers\n", options.resetVerb(), identitiesReset)
 		} else {
-			fmt.Printf("   ✓ No reactions found\n")
+			fmt.Fprintln(c.out, "   ✓ No server nicknames or profiles set")
 		}
-		fmt.Println()
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("4", phaseDone)
+	} else if !c.halted() {
+		fmt.Fprintln(c.out, "⏭️  Phase 4 skipped (not selected in profile).")
+		fmt.Fprintln(c.out)
+		c.progress.endPhase("4", phaseSkipped)
 	}
 
-	// Phase 3b: DM reactions
-	fmt.Println("   💬 Scanning DM channels for reactions...")
-	dmReactionCount := 0
-	for chID := range processedDMs {
-		removed := c.removeReactionsFromChannel(chID)
-		dmReactionCount += removed
-		if removed > 0 {
-			fmt.Printf("   ✅ Removed %d reactions from DM %s\n", removed, chID)
-		}
+	// =========================================================================
+	// Joined threads
+	// =========================================================================
+	var threadsLeft *ThreadLeaveResult
+	if !c.halted() {
+		threadsLeft = c.leaveThreads(guilds, &serverStats, options)
 	}
-	totalReactionsRemoved += dmReactionCount
 
-	if dmReactionCount == 0 {
-		fmt.Println("   ✓ No DM reactions found")
+	// =========================================================================
+	// DM housekeeping
+	// =========================================================================
+	var dmHousekeeping *DMHousekeepingResult
+	if !c.halted() {
+		dmHousekeeping = c.dmHousekeeping(reclosedDMs, processedDMs, knownDMs, options)
 	}
-	fmt.Println()
 
 	// =========================================================================
 	// Summary
 	// =========================================================================
+	stopControls()
+	liveStatus.Stop()
 	elapsed := time.Since(startTime).Round(time.Second)
-	fmt.Println(strings.Repeat("=", 70))
-	fmt.Println("✅ PURGE COMPLETE!")
-	fmt.Println(strings.Repeat("=", 70))
-	fmt.Println()
-	fmt.Printf("📊 TOTAL MESSAGES DELETED:        %d\n", totalDeleted)
-	fmt.Printf("👎 TOTAL REACTIONS REMOVED:       %d\n", totalReactionsRemoved)
-	fmt.Printf("💬 TOTAL DM MESSAGES DELETED:     %d\n", totalDMMessages)
-	fmt.Println()
-	fmt.Println("📈 PER-SERVER BREAKDOWN:")
-	fmt.Println(strings.Repeat("-", 70))
+	if c.halted() {
+		checkpoint.HaltReason = c.halt.Error()
+		printSafetyHalt(c.out, c.halt)
+		if options.DryRun {
+			fmt.Fprintln(c.out, "🧪 Dry run: no checkpoint saved.")
+		} else if err := checkpoint.Save(checkpointPath(options)); err != nil {
+			fmt.Fprintf(c.out, "❌ %v\n", err)
+		} else {
+			fmt.Fprintf(c.out, "💾 Progress saved to %s (stopped during Phase %s).\n", checkpointPath(options), checkpoint.Phase)
+			fmt.Fprintln(c.out, "   Run the tool again later and choose to resume from the checkpoint.")
+		}
+		fmt.Fprintln(c.out)
+		fmt.Fprintln(c.out, strings.Repeat("=", 70))
+		fmt.Fprintln(c.out, "🛑 PURGE HALTED — PARTIAL RESULTS")
+	} else if options.DryRun {
+		fmt.Fprintln(c.out, strings.Repeat("=", 70))
+		fmt.Fprintln(c.out, "🧪 DRY RUN COMPLETE — NOTHING WAS DELETED")
+	} else {
+		removeCheckpoint(c.out, checkpointPath(options))
+		fmt.Fprintln(c.out, strings.Repeat("=", 70))
+		fmt.Fprintln(c.out, "✅ PURGE COMPLETE!")
+	}
+	fmt.Fprintln(c.out, strings.Repeat("=", 70))
+	fmt.Fprintln(c.out)
+	if options.TargetAuthorID != "" {
+		fmt.Fprintf(c.out, "🛡️  TARGET USER:                  %s\n", options.TargetAuthorID)
+	}
+	if options.DryRun {
+		fmt.Fprintf(c.out, "📊 MESSAGES TO DELETE:            %d\n", totalDeleted)
+		fmt.Fprintf(c.out, "👎 REACTIONS TO REMOVE:           %d\n", reactionTotals.Reactions)
+		fmt.Fprintf(c.out, "✨ SUPER REACTIONS TO REMOVE:     %d\n", reactionTotals.SuperReactions)
+		fmt.Fprintf(c.out, "🗳️  POLL VOTES TO REMOVE:          %d\n", reactionTotals.PollVotes)
+		fmt.Fprintf(c.out, "💬 DM MESSAGES TO DELETE:         %d\n", totalDMMessages)
+	} else {
+		fmt.Fprintf(c.out, "📊 TOTAL MESSAGES DELETED:        %d\n", totalDeleted)
+		fmt.Fprintf(c.out, "👎 TOTAL REACTIONS REMOVED:       %d\n", reactionTotals.Reactions)
+		fmt.Fprintf(c.out, "✨ SUPER REACTIONS REMOVED:       %d\n", reactionTotals.SuperReactions)
+		fmt.Fprintf(c.out, "🗳️  POLL VOTES REMOVED:           %d\n", reactionTotals.PollVotes)
+		fmt.Fprintf(c.out, "💬 TOTAL DM MESSAGES DELETED:     %d\n", totalDMMessages)
+	}
+	if reactionTotals.ClosedPolls > 0 {
+		fmt.Fprintf(c.out, "🔒 CLOSED POLLS (VOTES KEPT):     %d\n", reactionTotals.ClosedPolls)
+	}
+	fmt.Fprintln(c.out)
+	fmt.Fprintln(c.out, "📈 PER-SERVER BREAKDOWN:")
+	fmt.Fprintln(c.out, strings.Repeat("-", 70))
 
+	messagesLabel, reactionsLabel, superLabel, votesLabel, identityLabel, threadsLabel := "Messages deleted: ", "Reactions removed:", "Super reactions:  ", "Poll votes:       ", "Profile reset:    ", "Threads left:     "
+	if options.DryRun {
+		messagesLabel, reactionsLabel, superLabel, votesLabel, identityLabel, threadsLabel = "Would delete:     ", "Would remove:     ", "Super to remove:  ", "Votes to remove:  ", "Would reset:      ", "Would leave:      "
+	}
 	if len(serverStats) == 0 {
-		fmt.Println("   No servers processed.")
+		fmt.Fprintln(c.out, "   No servers processed.")
 	} else {
 		for _, stat := range serverStats {
-			fmt.Printf("   🏠 %s\n", stat.GuildName)
-			fmt.Printf("      Messages deleted:  %d\n", stat.Messages)
-			fmt.Printf("      Reactions removed: %d\n", stat.Reactions)
-			fmt.Println()
+			fmt.Fprintf(c.out, "   🏠 %s\n", stat.GuildName)
+			fmt.Fprintf(c.out, "      %s %d\n", messagesLabel, stat.Messages)
+			fmt.Fprintf(c.out, "      %s %d\n", reactionsLabel, stat.Reactions)
+			if stat.SuperReactions > 0 {
+				fmt.Fprintf(c.out, "      %s %d\n", superLabel, stat.SuperReactions)
+			}
+			if stat.PollVotes > 0 {
+				fmt.Fprintf(c.out, "      %s %d\n", votesLabel, stat.PollVotes)
+			}
+			if stat.ClosedPolls > 0 {
+				fmt.Fprintf(c.out, "      Closed polls:      %d (votes kept)\n", stat.ClosedPolls)
+			}
+			if len(stat.IdentityReset) > 0 {
+				fmt.Fprintf(c.out, "      %s %s\n", identityLabel, strings.Join(stat.IdentityReset, ", "))
+			}
+			if stat.ThreadsLeft > 0 {
+				fmt.Fprintf(c.out, "      %s %d\n", threadsLabel, stat.ThreadsLeft)
+			}
+			fmt.Fprintln(c.out)
 		}
 	}
 
-	fmt.Println(strings.Repeat("-", 70))
-	fmt.Printf("⏱️  Time elapsed:                  %s\n", elapsed)
-	fmt.Printf("🏠 Servers processed:             %d\n", len(guilds))
-	fmt.Printf("💬 DM channels processed:         %d\n", len(processedDMs))
-	fmt.Println(strings.Repeat("=", 70))
-
-	return PurgeStats{
-		TotalMessagesDeleted:   totalDeleted,
-		TotalReactionsRemoved:  totalReactionsRemoved,
-		TotalDMMessagesDeleted: totalDMMessages,
-		ServerStats:            serverStats,
-		DMChannelsProcessed:    len(processedDMs),
-		TimeElapsed:            elapsed,
+	fmt.Fprintln(c.out, strings.Repeat("-", 70))
+	c.pacer.PrintReport(c.out)
+	if minutes := elapsed.Minutes(); minutes > 0 && totalDeleted > 0 {
+		if options.DryRun {
+			fmt.Fprintf(c.out, "   Effective rate: %.1f messages checked per minute\n", float64(totalDeleted)/minutes)
+		} else {
+			fmt.Fprintf(c.out, "   Effective rate: %.1f messages deleted per minute\n", float64(totalDeleted)/minutes)
+		}
+	}
+	if err := c.pacer.Save(pacingFile); err != nil {
+		fmt.Fprintf(c.out, "⚠️  %v\n", err)
+	}
+	fmt.Fprintln(c.out, strings.Repeat("-", 70))
+	fmt.Fprintf(c.out, "⏱️  Time elapsed:                  %s\n", elapsed)
+	fmt.Fprintf(c.out, "🏠 Servers processed:             %d\n", len(guilds))
+	fmt.Fprintf(c.out, "💬 DM channels processed:         %d\n", len(processedDMs))
+	if len(failedGuilds) > 0 {
+		fmt.Fprintf(c.out, "⚠️  Servers with errors:           %d\n", len(failedGuilds))
+	}
+	if len(failedDMs) > 0 {
+		fmt.Fprintf(c.out, "⚠️  DM channels with errors:       %d\n", len(failedDMs))
+	}
+	if len(skippedGuilds) > 0 {
+		fmt.Fprintf(c.out, "⏭️  Servers skipped on request:    %d\n", len(skippedGuilds))
+	}
+	if len(skippedDMs) > 0 {
+		fmt.Fprintf(c.out, "⏭️  DM channels skipped on request: %d\n", len(skippedDMs))
+	}
+	for _, listErr := range listErrors {
+		fmt.Fprintf(c.out, "⚠️  Error %s\n", listErr)
+	}
+	if threadsLeft != nil {
+		threadLabel := "Threads left:"
+		if options.DryRun {
+			threadLabel = "Threads to leave:"
+		}
+		fmt.Fprintf(c.out, "🧵 %-31s%d\n", threadLabel, threadsLeft.Left)
+		if threadsLeft.ArchivedKept > 0 {
+			fmt.Fprintf(c.out, "🧵 %-31s%d\n", "Archived threads kept:", threadsLeft.ArchivedKept)
+		}
+	}
+	if dmHousekeeping != nil {
+		fmt.Fprintf(c.out, "🧹 DMs re-closed:                 %d\n", dmHousekeeping.Reclosed)
+		closedLabel, leftLabel := "DMs closed:", "Group DMs left:"
+		if options.DryRun {
+			closedLabel, leftLabel = "DMs to close:", "Group DMs to leave:"
+		}
+		if options.CloseDMs {
+			fmt.Fprintf(c.out, "🧹 %-31s%d\n", closedLabel, dmHousekeeping.Closed)
+		}
+		if options.LeaveGroupDMs {
+			fmt.Fprintf(c.out, "🧹 %-31s%d\n", leftLabel, dmHousekeeping.GroupDMsLeft)
+		}
+	}
+	fmt.Fprintln(c.out, strings.Repeat("=", 70))
+
+	stats := PurgeStats{
+		TotalMessagesDeleted:       totalDeleted,
+		TotalReactionsRemoved:      reactionTotals.Reactions,
+		TotalSuperReactionsRemoved: reactionTotals.SuperReactions,
+		TotalPollVotesRemoved:      reactionTotals.PollVotes,
+		ClosedPollsKept:            reactionTotals.ClosedPolls,
+		TotalDMMessagesDeleted:     totalDMMessages,
+		ServerStats:                serverStats,
+		DMChannelsProcessed:        len(processedDMs),
+		TargetAuthorID:             options.TargetAuthorID,
+		DryRun:                     options.DryRun,
+		DMHousekeeping:             dmHousekeeping,
+		Threads:                    threadsLeft,
+		TimeElapsed:                elapsed,
+		FailedGuilds:               failedGuilds,
+		FailedDMChannels:           failedDMs,
+		SkippedGuilds:              skippedGuilds,
+		SkippedDMChannels:          skippedDMs,
+		Errors:                     listErrors,
 	}
+	if c.halted() {
+		stats.Halted = true
+		stats.HaltReason = c.halt.Error()
+	}
+	c.progress.finish(stats)
+	return stats
 }
 
 // =============================================================================
-// Friend removal and server leaving
+// Relationship removal and server leaving
 // =============================================================================
 
-// RemoveFriend removes a friend relationship.
-func (c *DiscordClient) RemoveFriend(userID string) error {
+// RemoveRelationship removes the relationship with a user: it unfriends,
+// unblocks, or cancels or declines a pending friend request.
+func (c *DiscordClient) RemoveRelationship(userID string) error {
 	_, status, err := c.request("DELETE", fmt.Sprintf("/users/@me/relationships/%s", userID))
 	if err != nil {
 		return err
@@ -1551,54 +3178,135 @@ func (c *DiscordClient) LeaveGuild(guildID string) error {
 	return fmt.Errorf("HTTP %d", status)
 }
 
-// RemoveAllFriends removes all friends from the user's friend list.
-func (c *DiscordClient) RemoveAllFriends() (int, error) {
-	rels, err := c.GetRelationships()
+// =============================================================================
+// User notes and authorized applications
+// =============================================================================
+
+// AuthorizedApp is a third-party application holding an OAuth2 token for the
+// account.
+type AuthorizedApp struct {
+	TokenID       string   `json:"token_id"`
+	ApplicationID string   `json:"application_id"`
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+}
+
+// GetUserNotes returns the private notes the user has written on other users,
+// keyed by user ID.
+func (c *DiscordClient) GetUserNotes() (map[string]string, error) {
+	body, status, err := c.request("GET", "/users/@me/notes")
 	if err != nil {
-		return 0, fmt.Errorf("fetching relationships: %w", err)
+		return nil, fmt.Errorf("fetching notes: %w", err)
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("fetching notes: HTTP %d", status)
+	}
+
+	var notes map[string]string
+	if err := json.Unmarshal(body, &notes); err != nil {
+		return nil, fmt.Errorf("parsing notes: %w", err)
+	}
+	return notes, nil
+}
+
+// GetAuthorizedApps lists the applications the user has authorized.
+func (c *DiscordClient) GetAuthorizedApps() ([]AuthorizedApp, error) {
+	body, status, err := c.request("GET", "/oauth2/tokens")
+	if err != nil {
+		return nil, fmt.Errorf("fetching authorized apps: %w", err)
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("fetching authorized apps: HTTP %d", status)
+	}
+
+	var tokens []struct {
+		ID          string   `json:"id"`
+		Scopes      []string `json:"scopes"`
+		Application struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"application"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("parsing authorized apps: %w", err)
+	}
+	apps := make([]AuthorizedApp, 0, len(tokens))
+	for _, token := range tokens {
+		apps = append(apps, AuthorizedApp{
+			TokenID:       token.ID,
+			ApplicationID: token.Application.ID,
+			Name:          token.Application.Name,
+			Scopes:        token.Scopes,
+		})
+	}
+	return apps, nil
+}
+
+// ClearAllNotes empties every private user note and returns the IDs of the
+// users whose notes were cleared. A dry run only lists them.
+func (c *DiscordClient) ClearAllNotes(dryRun bool) ([]string, error) {
+	notes, err := c.GetUserNotes()
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0, len(notes))
+	for userID, note := range notes {
+		if note != "" {
+			userIDs = append(userIDs, userID)
+		}
 	}
+	sort.Strings(userIDs)
 
-	removedCount := 0
-	for _, rel := range rels {
-		if rel.Type == RelationshipFriend {
-			err := c.RemoveFriend(rel.User.ID)
-			if err != nil {
-				fmt.Printf("   ⚠️  Failed to remove friend %s: %v\n", rel.User.Username, err)
-			} else {
-				removedCount++
-				fmt.Printf("   ✅ Removed friend: %s\n", rel.User.Username)
-			}
-			time.Sleep(500 * time.Millisecond) // Rate limit protection
+	var cleared []string
+	for _, userID := range userIDs {
+		if dryRun {
+			cleared = append(cleared, userID)
+			fmt.Fprintf(c.out, "   🧪 Would clear note on user %s\n", userID)
+			continue
+		}
+		_, status, err := c.requestWithBody("PUT", fmt.Sprintf("/users/@me/notes/%s", userID), `{"note":""}`)
+		if isSafetyHalt(err) {
+			return cleared, err
+		}
+		if err == nil && (status == 204 || status == 200) {
+			cleared = append(cleared, userID)
+			fmt.Fprintf(c.out, "   ✅ Cleared note on user %s\n", userID)
+		} else {
+			fmt.Fprintf(c.out, "   ⚠️  Failed to clear note on user %s: %v\n", userID, statusError(status, err))
 		}
+		c.pacer.Wait(paceDiscovery)
 	}
-
-	return removedCount, nil
+	return cleared, nil
 }
 
-// LeaveAllGuilds leaves all servers the user is a member of.
-func (c *DiscordClient) LeaveAllGuilds() (int, error) {
-	guilds, err := c.GetAllGuilds()
+// RevokeAllAuthorizedApps revokes every authorized application's token and
+// returns the applications revoked. A dry run only lists them.
+func (c *DiscordClient) RevokeAllAuthorizedApps(dryRun bool) ([]AuthorizedApp, error) {
+	apps, err := c.GetAuthorizedApps()
 	if err != nil {
-		return 0, fmt.Errorf("fetching guilds: %w", err)
+		return nil, err
 	}
 
-	leftCount := 0
-	for _, guild := range guilds {
-		name := guild.Name
-		if name == "" {
-			name = guild.ID
+	var revoked []AuthorizedApp
+	for _, app := range apps {
+		if dryRun {
+			revoked = append(revoked, app)
+			fmt.Fprintf(c.out, "   🧪 Would revoke %s (%s)\n", app.Name, strings.Join(app.Scopes, ", "))
+			continue
 		}
-		err := c.LeaveGuild(guild.ID)
-		if err != nil {
-			fmt.Printf("   ⚠️  Failed to leave server %s: %v\n", name, err)
+		_, status, err := c.request("DELETE", fmt.Sprintf("/oauth2/tokens/%s", app.TokenID))
+		if isSafetyHalt(err) {
+			return revoked, err
+		}
+		if err == nil && (status == 204 || status == 200 || status == 404) {
+			revoked = append(revoked, app)
+			fmt.Fprintf(c.out, "   ✅ Revoked %s\n", app.Name)
 		} else {
-			leftCount++
-			fmt.Printf("   ✅ Left server: %s\n", name)
+			fmt.Fprintf(c.out, "   ⚠️  Failed to revoke %s: %v\n", app.Name, statusError(status, err))
 		}
-		time.Sleep(500 * time.Millisecond) // Rate limit protection
+		c.pacer.Wait(paceDiscovery)
 	}
-
-	return leftCount, nil
+	return revoked, nil
 }
 
 // describeChannel returns a human-readable label for a DM channel.
@@ -1620,6 +3328,21 @@ func describeChannel(ch Channel) string {
 	return fmt.Sprintf("Group: %s", strings.Join(names, ", "))
 }
 
+// relationshipLabel describes a relationship type for display.
+func relationshipLabel(relType int) string {
+	switch relType {
+	case RelationshipFriend:
+		return "friend"
+	case RelationshipBlocked:
+		return "blocked"
+	case RelationshipIncomingReq:
+		return "incoming request"
+	case RelationshipOutgoingReq:
+		return "outgoing request"
+	}
+	return "related"
+}
+
 func displayGuildName(guild Guild) string {
 	if guild.Name != "" {
 		return guild.Name
@@ -1708,19 +3431,39 @@ func promptSelection(reader *bufio.Reader, prompt string, max int) map[int]bool
 	}
 }
 
-func promptPurgeOptions(guilds []Guild, dmChannels []Channel) PurgeOptions {
+func promptPurgeOptions(client *DiscordClient, guilds []Guild, dmChannels []Channel) PurgeOptions {
 	options := PurgeOptions{
 		ExcludedGuildIDs:     make(map[string]bool),
 		ExcludedDMChannelIDs: make(map[string]bool),
+		ExcludedChannelIDs:   make(map[string]bool),
+		ExcludedCategoryIDs:  make(map[string]bool),
+		IncludedGuildIDs:     make(map[string]bool),
+		IncludedDMChannelIDs: make(map[string]bool),
+		IncludedChannelIDs:   make(map[string]bool),
 	}
 
 	fmt.Println("🧭 Optional scope selection")
 	fmt.Println("By default, the purge covers everything reachable on your account.")
-	fmt.Println("You can exclude specific servers and DM/group DM channels before starting.")
+	fmt.Println("You can exclude specific servers and DM/group DM channels before starting,")
+	fmt.Println("or switch to include-only mode and pick just the items to purge.")
 	fmt.Println()
 
 	reader := bufio.NewReader(os.Stdin)
 
+	fmt.Print("Scope mode — [E]xclude selected items or [I]nclude only selected items? (E/i): ")
+	modeInput, _ := reader.ReadString('\n')
+	includeOnly := strings.HasPrefix(strings.TrimSpace(strings.ToLower(modeInput)), "i")
+	fmt.Println()
+
+	verb := "EXCLUDE"
+	guildTargets := options.ExcludedGuildIDs
+	dmTargets := options.ExcludedDMChannelIDs
+	if includeOnly {
+		verb = "INCLUDE"
+		guildTargets = options.IncludedGuildIDs
+		dmTargets = options.IncludedDMChannelIDs
+	}
+
 	if len(guilds) > 0 {
 		fmt.Println("Servers:")
 		for i, guild := range guilds {
@@ -1730,16 +3473,58 @@ func promptPurgeOptions(guilds []Guild, dmChannels []Channel) PurgeOptions {
 
 		selectedGuilds := promptSelection(
 			reader,
-			"Enter server numbers to EXCLUDE (e.g. 1,3-5) or press Enter for none: ",
+			fmt.Sprintf("Enter server numbers to %s (e.g. 1,3-5) or press Enter for none: ", verb),
 			len(guilds),
 		)
 		for i, guild := range guilds {
 			if selectedGuilds[i+1] {
-				options.ExcludedGuildIDs[guild.ID] = true
+				guildTargets[guild.ID] = true
+			}
+		}
+
+		// Optional channel-level selection inside individual servers
+		for {
+			fmt.Println()
+			fmt.Printf("Enter a server number to %s individual channels, categories or threads in it,\n", verb)
+			fmt.Print("or press Enter to continue: ")
+			input, _ := reader.ReadString('\n')
+			input = strings.TrimSpace(input)
+			if input == "" {
+				break
+			}
+			idx, err := strconv.Atoi(input)
+			if err != nil || idx < 1 || idx > len(guilds) {
+				fmt.Printf("❌ Enter a single server number between 1 and %d.\n", len(guilds))
+				continue
+			}
+			guild := guilds[idx-1]
+			if !includeOnly && options.ExcludedGuildIDs[guild.ID] {
+				fmt.Println("   That server is already excluded entirely.")
+				continue
+			}
+			if includeOnly && options.IncludedGuildIDs[guild.ID] {
+				fmt.Println("   That server is already included entirely.")
+				continue
+			}
+
+			categoryIDs, channelIDs := promptGuildChannelSelection(reader, client, guild, verb)
+			for _, id := range categoryIDs {
+				if includeOnly {
+					options.IncludedChannelIDs[id] = true
+				} else {
+					options.ExcludedCategoryIDs[id] = true
+				}
+			}
+			for _, id := range channelIDs {
+				if includeOnly {
+					options.IncludedChannelIDs[id] = true
+				} else {
+					options.ExcludedChannelIDs[id] = true
+				}
 			}
 		}
 	} else {
-		fmt.Println("No servers found to list for exclusion.")
+		fmt.Println("No servers found to list for selection.")
 	}
 
 	fmt.Println()
@@ -1757,36 +3542,230 @@ func promptPurgeOptions(guilds []Guild, dmChannels []Channel) PurgeOptions {
 
 		selectedDMs := promptSelection(
 			reader,
-			"Enter DM/channel numbers to EXCLUDE (e.g. 2,4-6) or press Enter for none: ",
+			fmt.Sprintf("Enter DM/channel numbers to %s (e.g. 2,4-6) or press Enter for none: ", verb),
 			len(dmChannels),
 		)
 		for i, ch := range dmChannels {
 			if selectedDMs[i+1] {
-				options.ExcludedDMChannelIDs[ch.ID] = true
+				dmTargets[ch.ID] = true
 			}
 		}
 	} else {
-		fmt.Println("No open DM channels found to list for exclusion.")
+		fmt.Println("No open DM channels found to list for selection.")
 	}
 
 	fmt.Println()
-	fmt.Printf(
-		"✅ Exclusions selected: %d servers, %d DM/group DM channels.\n",
-		len(options.ExcludedGuildIDs),
-		len(options.ExcludedDMChannelIDs),
-	)
-	if len(options.ExcludedGuildIDs) > 0 || len(options.ExcludedDMChannelIDs) > 0 {
-		fmt.Println("   Excluded items will be skipped during message deletion and reaction removal.")
+	if includeOnly {
+		fmt.Printf(
+			"✅ Include-only scope: %d servers, %d DM/group DM channels, %d server channels/categories.\n",
+			len(options.IncludedGuildIDs),
+			len(options.IncludedDMChannelIDs),
+			len(options.IncludedChannelIDs),
+		)
+		if !options.includeMode() {
+			// An empty include list would otherwise fall back to purging everything.
+			fmt.Println("❌ Include-only mode needs at least one selected server, channel or DM.")
+			fmt.Println("   Starting the scope selection again.")
+			fmt.Println()
+			return promptPurgeOptions(client, guilds, dmChannels)
+		}
+		fmt.Println("   Everything else will be left untouched, including hidden DMs.")
+	} else {
+		fmt.Printf(
+			"✅ Exclusions selected: %d servers, %d DM/group DM channels, %d channels/threads, %d categories.\n",
+			len(options.ExcludedGuildIDs),
+			len(options.ExcludedDMChannelIDs),
+			len(options.ExcludedChannelIDs),
+			len(options.ExcludedCategoryIDs),
+		)
+		if len(options.ExcludedGuildIDs) > 0 || len(options.ExcludedDMChannelIDs) > 0 ||
+			len(options.ExcludedChannelIDs) > 0 || len(options.ExcludedCategoryIDs) > 0 {
+			fmt.Println("   Excluded items will be skipped during message deletion and reaction removal.")
+		}
 	}
 	fmt.Println()
 
 	return options
 }
 
+// promptGuildChannelSelection lists a guild's categories, channels and active
+// threads (grouped by category) and returns the selected category IDs and
+// channel/thread IDs.
+func promptGuildChannelSelection(reader *bufio.Reader, client *DiscordClient, guild Guild, verb string) ([]string, []string) {
+	channels, err := client.GetGuildChannels(guild.ID)
+	if err != nil || len(channels) == 0 {
+		fmt.Printf("⚠️  Could not load channels for %s.\n", displayGuildName(guild))
+		return nil, nil
+	}
+	threads, _ := client.GetActiveGuildThreads(guild.ID)
+
+	children := make(map[string][]Channel)
+	var categories []Channel
+	for _, ch := range append(channels, threads...) {
+		if ch.Type == ChannelTypeGuildCategory {
+			categories = append(categories, ch)
+			continue
+		}
+		children[ch.ParentID] = append(children[ch.ParentID], ch)
+	}
+
+	type entry struct {
+		channel Channel
+		label   string
+	}
+	var entries []entry
+	var addChildren func(parentID, indent string)
+	addChildren = func(parentID, indent string) {
+		for _, ch := range children[parentID] {
+			prefix := "#"
+			switch ch.Type {
+			case ChannelTypeGuildNewsThread, ChannelTypeGuildPublicThread, ChannelTypeGuildPrivateThread:
+				prefix = "🧵"
+			case ChannelTypeGuildVoice, ChannelTypeGuildStageVoice:
+				prefix = "🔊"
+			case ChannelTypeGuildForum, ChannelTypeGuildMedia:
+				prefix = "💬"
+			}
+			entries = append(entries, entry{ch, fmt.Sprintf("%s%s %s", indent, prefix, ch.Name)})
+			addChildren(ch.ID, indent+"    ")
+		}
+	}
+	addChildren("", "")
+	for _, cat := range categories {
+		entries = append(entries, entry{cat, fmt.Sprintf("📁 %s (category — covers everything below)", cat.Name)})
+		addChildren(cat.ID, "    ")
+	}
+
+	fmt.Printf("Channels in %s:\n", displayGuildName(guild))
+	for i, e := range entries {
+		fmt.Printf("  [%d] %s (ID: %s)\n", i+1, e.label, e.channel.ID)
+	}
+	fmt.Println()
+
+	selected := promptSelection(
+		reader,
+		fmt.Sprintf("Enter channel numbers to %s (e.g. 1,3-5) or press Enter for none: ", verb),
+		len(entries),
+	)
+
+	var categoryIDs, channelIDs []string
+	for i, e := range entries {
+		if !selected[i+1] {
+			continue
+		}
+		if e.channel.Type == ChannelTypeGuildCategory {
+			categoryIDs = append(categoryIDs, e.channel.ID)
+		} else {
+			channelIDs = append(channelIDs, e.channel.ID)
+		}
+	}
+	return categoryIDs, channelIDs
+}
+
 // =============================================================================
 // User interaction
 // =============================================================================
 
+// cliArgs holds the parsed command-line options.
+type cliArgs struct {
+	DataPackagePath string
+	ConfigPath      string
+	ProfileName     string
+	Daemon          bool
+	Web             bool
+	DryRun          bool
+	Bot             bool
+	CloseDMs        bool
+	LeaveGroupDMs   bool
+	LeaveThreads    bool
+	ModerateAuthor  string
+	ModerateGuilds  []string
+	RestoreProfile  string
+}
+
+func parseArgs(args []string) (cliArgs, error) {
+	var parsed cliArgs
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires an argument", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch arg {
+		case "--data-package", "-d":
+			parsed.DataPackagePath, err = value()
+		case "--config", "-c":
+			parsed.ConfigPath, err = value()
+		case "--profile", "-p":
+			parsed.ProfileName, err = value()
+		case "--daemon":
+			parsed.Daemon = true
+		case "--web":
+			parsed.Web = true
+		case "--dry-run":
+			parsed.DryRun = true
+		case "--bot":
+			parsed.Bot = true
+		case "--close-dms":
+			parsed.CloseDMs = true
+		case "--leave-group-dms":
+			parsed.LeaveGroupDMs = true
+		case "--leave-threads":
+			parsed.LeaveThreads = true
+		case "--restore-profile":
+			parsed.RestoreProfile, err = value()
+		case "--moderate":
+			parsed.ModerateAuthor, err = value()
+		case "--guild":
+			var guilds string
+			guilds, err = value()
+			parsed.ModerateGuilds = append(parsed.ModerateGuilds, splitIDList(guilds)...)
+		default:
+			err = fmt.Errorf("unknown option %s", arg)
+		}
+		if err != nil {
+			return parsed, err
+		}
+	}
+
+	if parsed.ProfileName != "" && parsed.ConfigPath == "" {
+		return parsed, fmt.Errorf("--profile requires --config")
+	}
+	if parsed.Daemon && parsed.ConfigPath == "" {
+		return parsed, fmt.Errorf("--daemon requires --config")
+	}
+	if parsed.Daemon && parsed.Web {
+		return parsed, fmt.Errorf("--daemon and --web cannot be combined")
+	}
+	if parsed.RestoreProfile != "" && (parsed.Daemon || parsed.Web || parsed.ModerateAuthor != "") {
+		return parsed, fmt.Errorf("--restore-profile runs on its own")
+	}
+	if parsed.Daemon && parsed.DryRun {
+		return parsed, fmt.Errorf("--daemon and --dry-run cannot be combined")
+	}
+	if parsed.ModerateAuthor != "" && !isSnowflake(parsed.ModerateAuthor) {
+		return parsed, fmt.Errorf("--moderate: %q is not a valid Discord user ID", parsed.ModerateAuthor)
+	}
+	for _, id := range parsed.ModerateGuilds {
+		if !isSnowflake(id) {
+			return parsed, fmt.Errorf("--guild: %q is not a valid Discord server ID", id)
+		}
+	}
+	if len(parsed.ModerateGuilds) > 0 && parsed.ModerateAuthor == "" {
+		return parsed, fmt.Errorf("--guild requires --moderate")
+	}
+	if parsed.ModerateAuthor != "" && (parsed.Daemon || parsed.Web) {
+		return parsed, fmt.Errorf("--moderate cannot be combined with --daemon or --web")
+	}
+	return parsed, nil
+}
+
 func main() {
 	fmt.Println("╔══════════════════════════════════════════════════════╗")
 	fmt.Println("║          Discord Message Purge Tool                 ║")
@@ -1794,17 +3773,44 @@ func main() {
 	fmt.Println("╚══════════════════════════════════════════════════════╝")
 	fmt.Println()
 
-	// Parse optional --data-package flag
-	dataPackagePath := ""
-	for i, arg := range os.Args[1:] {
-		if arg == "--data-package" || arg == "-d" {
-			if i+1 < len(os.Args[1:]) {
-				dataPackagePath = os.Args[i+2]
-			} else {
-				fmt.Println("❌ --data-package requires a path argument")
-				os.Exit(1)
-			}
+	args, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	dataPackagePath := args.DataPackagePath
+
+	// Load and validate the configuration profile before anything else
+	var profile *Profile
+	profileName := ""
+	if args.ConfigPath != "" {
+		cfg, err := LoadConfig(args.ConfigPath)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		profileName, profile, err = cfg.Profile(args.ProfileName)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
 		}
+		if err := profile.Validate(); err != nil {
+			fmt.Printf("❌ Profile %q: %v\n", profileName, err)
+			os.Exit(1)
+		}
+		if args.Daemon && profile.Daemon.RetainDays <= 0 {
+			fmt.Printf("❌ Profile %q: --daemon requires daemon.retain_days\n", profileName)
+			os.Exit(1)
+		}
+		if args.Web && profile.Moderate.AuthorID != "" {
+			fmt.Printf("❌ Profile %q: moderate cannot be used with --web\n", profileName)
+			os.Exit(1)
+		}
+		if dataPackagePath == "" {
+			dataPackagePath = profile.DataPackage
+		}
+		fmt.Printf("✅ Using profile %q from %s\n", profileName, args.ConfigPath)
+		fmt.Println()
 	}
 
 	// Check for token in environment variable first
@@ -1826,9 +3832,21 @@ func main() {
 
 	// Create client and authenticate
 	client := NewDiscordClient(token)
+	client.bot = args.Bot
+	if base := os.Getenv("DISCORD_API_BASE"); base != "" {
+		client.baseURL = strings.TrimRight(base, "/")
+		fmt.Printf("⚠️  Using API base URL from DISCORD_API_BASE: %s\n", client.baseURL)
+		fmt.Println()
+	}
+	if err := client.pacer.Load(pacingFile); err != nil {
+		fmt.Printf("⚠️  Ignoring saved pacing: %v\n", err)
+	}
+	if profile != nil {
+		profile.ApplyPacing(client.pacer)
+	}
 
 	fmt.Println("🔐 Authenticating...")
-	err := client.Authenticate()
+	err = client.Authenticate()
 	if err != nil {
 		fmt.Printf("❌ Authentication failed: %v\n", err)
 		fmt.Println()
@@ -1842,6 +3860,24 @@ func main() {
 	fmt.Printf("✅ Authenticated as: %s (ID: %s)\n", client.username, client.userID)
 	fmt.Println()
 
+	if args.RestoreProfile != "" {
+		os.Exit(restoreProfile(client, args.RestoreProfile))
+	}
+
+	if client.bot {
+		fmt.Println("🤖 Bot token detected: messages of a target user are deleted in the bot's servers.")
+		fmt.Println("   Search, DMs and reactions aren't available to bots; channel histories are walked instead.")
+		fmt.Println()
+		if args.Daemon || args.Web {
+			fmt.Println("❌ --daemon and --web need a user token.")
+			os.Exit(1)
+		}
+		if args.ModerateAuthor == "" && (profile == nil || profile.Moderate.AuthorID == "") {
+			fmt.Println("❌ Bot tokens need a target: pass --moderate USER_ID (your own user ID to clean up your messages).")
+			os.Exit(1)
+		}
+	}
+
 	purgeOptions := PurgeOptions{
 		ExcludedGuildIDs:     make(map[string]bool),
 		ExcludedDMChannelIDs: make(map[string]bool),
@@ -1854,24 +3890,132 @@ func main() {
 		selectionGuilds = []Guild{}
 	}
 
-	selectionDMs, dmErr := client.GetDMChannels()
+	var selectionDMs []Channel
+	var dmErr error
+	if !client.bot {
+		selectionDMs, dmErr = client.GetDMChannels()
+	}
 	if dmErr != nil {
 		fmt.Printf("⚠️  Could not load DM channel list for exclusions: %v\n", dmErr)
 		selectionDMs = []Channel{}
 	}
 
-	if guildErr == nil || dmErr == nil {
+	if client.halted() {
+		printSafetyHalt(client.out, client.halt)
+		os.Exit(2)
+	}
+
+	if profile != nil {
+		switch {
+		case guildErr == nil:
+			if err := profile.ValidateGuilds(selectionGuilds); err != nil {
+				fmt.Printf("❌ Profile %q: %v\n", profileName, err)
+				os.Exit(1)
+			}
+		case len(profile.IncludedGuildIDs) > 0:
+			// An unchecked include list could name the wrong servers.
+			fmt.Printf("❌ Profile %q includes specific servers, but they can't be checked without the server list.\n", profileName)
+			os.Exit(1)
+		default:
+			fmt.Printf("⚠️  Profile %q: server IDs were not checked against your server list.\n", profileName)
+		}
+		purgeOptions = profile.PurgeOptions()
+		fmt.Println()
+		fmt.Printf(
+			"✅ Profile scope: excluding %d servers, %d DM/group DM channels.\n",
+			len(purgeOptions.ExcludedGuildIDs),
+			len(purgeOptions.ExcludedDMChannelIDs),
+		)
+		if purgeOptions.includeMode() {
+			fmt.Printf(
+				"   Include-only: %d servers, %d DM/group DM channels, %d server channels.\n",
+				len(purgeOptions.IncludedGuildIDs),
+				len(purgeOptions.IncludedDMChannelIDs),
+				len(purgeOptions.IncludedChannelIDs),
+			)
+		}
+		fmt.Println()
+		if args.Daemon {
+			os.Exit(runDaemon(client, profile, dataPackagePath))
+		}
+	} else if args.Web {
+		// The scope is picked in the browser.
+	} else if args.ModerateAuthor != "" {
+		// The scope is the --guild list.
+	} else if guildErr == nil || dmErr == nil {
 		fmt.Println()
-		purgeOptions = promptPurgeOptions(selectionGuilds, selectionDMs)
+		purgeOptions = promptPurgeOptions(client, selectionGuilds, selectionDMs)
 	} else {
 		fmt.Println("⚠️  Exclusion selection unavailable; continuing with full deletion scope.")
 		fmt.Println()
 	}
 
-	// Confirmation
-	if !confirmDeletion() {
-		fmt.Println("Operation cancelled.")
-		os.Exit(0)
+	if args.DryRun {
+		purgeOptions.DryRun = true
+	}
+	if args.CloseDMs {
+		purgeOptions.CloseDMs = true
+	}
+	if args.LeaveGroupDMs {
+		purgeOptions.LeaveGroupDMs = true
+	}
+	if args.LeaveThreads {
+		purgeOptions.LeaveThreads = true
+	}
+	if args.ModerateAuthor != "" {
+		guildIDs := args.ModerateGuilds
+		if len(guildIDs) == 0 && profile != nil {
+			guildIDs = profile.Moderate.Guilds
+		}
+		if len(guildIDs) == 0 && client.bot {
+			for _, guild := range selectionGuilds {
+				guildIDs = append(guildIDs, guild.ID)
+			}
+		}
+		if len(guildIDs) == 0 {
+			fmt.Println("❌ --moderate requires --guild (or moderate.guilds in the profile)")
+			os.Exit(1)
+		}
+		purgeOptions = moderationOptions(purgeOptions, args.ModerateAuthor, guildIDs)
+	}
+
+	if args.Web {
+		os.Exit(runWebUI(client, dataPackagePath, profileName, purgeOptions, selectionGuilds, selectionDMs))
+	}
+
+	if purgeOptions.TargetAuthorID != "" {
+		if purgeOptions.TargetAuthorID == client.userID {
+			fmt.Println("❌ Moderator mode targets other users; run without --moderate to delete your own messages.")
+			os.Exit(1)
+		}
+		if guildErr != nil {
+			fmt.Println("❌ Moderator mode needs the server list to check permissions.")
+			os.Exit(1)
+		}
+		if !client.checkModeratorAccess(selectionGuilds, &purgeOptions) {
+			if client.halted() {
+				printSafetyHalt(client.out, client.halt)
+				os.Exit(2)
+			}
+			fmt.Println("❌ None of the selected servers grants you Manage Messages.")
+			os.Exit(1)
+		}
+	}
+
+	// Dry runs change nothing, so they neither resume nor ask for confirmation.
+	if !purgeOptions.DryRun {
+		purgeOptions.Resume = promptResumeCheckpoint(client.userID, purgeOptions)
+
+		var confirmed bool
+		if purgeOptions.TargetAuthorID != "" {
+			confirmed = confirmModeration(purgeOptions.TargetAuthorID, len(purgeOptions.IncludedGuildIDs))
+		} else {
+			confirmed = confirmDeletion()
+		}
+		if !confirmed {
+			fmt.Println("Operation cancelled.")
+			os.Exit(0)
+		}
 	}
 
 	fmt.Println()
@@ -1880,33 +4024,121 @@ func main() {
 	fmt.Println()
 
 	stats := client.PurgeAll(dataPackagePath, purgeOptions)
+	report := client.NewRunReport(profileName, stats)
+
+	if stats.Halted {
+		fmt.Println()
+		fmt.Println("Cleanup skipped because the run was halted. Friends and servers remain unchanged.")
+		writeReport(report, purgeOptions.ReportPath)
+		os.Exit(2)
+	}
+
+	if purgeOptions.TargetAuthorID != "" {
+		fmt.Println()
+		fmt.Println("Cleanup skipped (moderator mode). Friends and servers remain unchanged.")
+		writeReport(report, purgeOptions.ReportPath)
+		return
+	}
+	if purgeOptions.DryRun {
+		fmt.Println()
+		fmt.Println("Cleanup skipped (dry run). Friends, servers and your profile remain unchanged.")
+		report.Cleanup = previewTraceCleanup(client, profile)
+		writeReport(report, purgeOptions.ReportPath)
+		return
+	}
 
-	// Ask if user wants to remove friends and leave servers
+	// Ask if user wants to remove friends, leave servers and scrub their
+	// profile, unless the profile already decided
+	var cleanup CleanupChoice
 	fmt.Println()
-	if confirmCleanup() {
+	if profile != nil && profile.Cleanup != nil {
+		cleanup = *profile.Cleanup
+	} else {
+		cleanup.LeaveServers = confirmServerLeave()
 		fmt.Println()
-		fmt.Println("🗑️  Removing all friends and leaving all servers...")
+		promptRelationshipCleanup(client, &cleanup)
+		cleanup.ScrubProfile = confirmProfileScrub()
 		fmt.Println()
+		if confirmTraceCleanup() {
+			cleanup.ClearNotes, cleanup.RevokeApps = true, true
+		}
+	}
 
-		// Remove friends
-		fmt.Println("👥 Removing friends...")
-		friendsRemoved, err := client.RemoveAllFriends()
-		if err != nil {
-			fmt.Printf("❌ Error removing friends: %v\n", err)
-		} else {
-			fmt.Printf("✅ Removed %d friends.\n", friendsRemoved)
+	// Servers to leave are listed up front; interactively they are confirmed
+	// and owned servers can be handed to a new owner.
+	var leavePlan *ServerLeavePlan
+	if cleanup.LeaveServers {
+		leavePlan, err = client.planServerLeave(purgeOptions, cleanup)
+		switch {
+		case err != nil:
+			fmt.Printf("❌ Error planning server leave: %v\n", err)
+			cleanup.LeaveServers = false
+		case profile != nil && profile.Cleanup != nil:
+			leavePlan.print()
+		default:
+			fmt.Println()
+			cleanup.LeaveServers = promptServerLeave(leavePlan)
 		}
+	}
+
+	if cleanup.any() {
+		fmt.Println()
+		fmt.Println("🗑️  Running cleanup...")
 		fmt.Println()
 
-		// Leave servers
-		fmt.Println("🚪 Leaving servers...")
-		serversLeft, err := client.LeaveAllGuilds()
-		if err != nil {
-			fmt.Printf("❌ Error leaving servers: %v\n", err)
-		} else {
+		friendsRemoved := 0
+		var relationships *RelationshipCleanupResult
+		if rc := cleanup.relationshipCleanup(); rc.any() {
+			fmt.Println("👥 Cleaning up relationships...")
+			relationships, err = client.CleanupRelationships(rc)
+			if err != nil {
+				fmt.Printf("❌ Error cleaning up relationships: %v\n", err)
+			}
+			if relationships != nil {
+				friendsRemoved = relationships.FriendsRemoved
+				fmt.Printf("✅ Removed %d friends, unblocked %d users, cancelled %d and declined %d friend requests.\n",
+					relationships.FriendsRemoved, relationships.Unblocked, relationships.OutgoingCancelled, relationships.IncomingDeclined)
+			}
+			fmt.Println()
+		}
+
+		serversLeft := 0
+		var serverLeave *ServerLeaveResult
+		if cleanup.LeaveServers {
+			fmt.Println("🚪 Leaving servers...")
+			serverLeave, err = client.LeaveServers(leavePlan)
+			if err != nil {
+				fmt.Printf("❌ Error leaving servers: %v\n", err)
+			}
+			serversLeft = len(serverLeave.Left)
 			fmt.Printf("✅ Left %d servers.\n", serversLeft)
+			if len(serverLeave.OwnedKept) > 0 {
+				fmt.Printf("   Still the owner of: %s\n", strings.Join(serverLeave.OwnedKept, ", "))
+			}
+			fmt.Println()
 		}
-		fmt.Println()
+
+		report.Cleanup = &CleanupReport{
+			FriendsRemoved: friendsRemoved,
+			ServersLeft:    serversLeft,
+			Relationships:  relationships,
+			ServerLeave:    serverLeave,
+		}
+
+		if cleanup.ScrubProfile {
+			fmt.Println("🪪 Scrubbing your profile...")
+			scrub, err := client.ScrubProfile()
+			if err != nil {
+				fmt.Printf("❌ Error scrubbing profile: %v\n", err)
+			} else {
+				fmt.Printf("✅ Reset %d profile fields and removed %d connections.\n", len(scrub.FieldsReset), scrub.ConnectionsRemoved)
+				fmt.Printf("   Restore later with: discord-purge --restore-profile %s\n", scrub.BackupPath)
+			}
+			report.Cleanup.ProfileScrub = scrub
+			fmt.Println()
+		}
+
+		clearTraces(client, cleanup, report.Cleanup, false)
 
 		fmt.Println(strings.Repeat("=", 70))
 		fmt.Println("✅ CLEANUP COMPLETE!")
@@ -1917,12 +4149,115 @@ func main() {
 		fmt.Printf("   • Reactions removed:       %d\n", stats.TotalReactionsRemoved)
 		fmt.Printf("   • DM messages deleted:     %d\n", stats.TotalDMMessagesDeleted)
 		fmt.Printf("   • Friends removed:        %d\n", friendsRemoved)
+		if relationships != nil {
+			fmt.Printf("   • Users unblocked:        %d\n", relationships.Unblocked)
+			fmt.Printf("   • Requests cancelled:     %d\n", relationships.OutgoingCancelled)
+			fmt.Printf("   • Requests declined:      %d\n", relationships.IncomingDeclined)
+		}
 		fmt.Printf("   • Servers left:           %d\n", serversLeft)
+		if scrub := report.Cleanup.ProfileScrub; scrub != nil {
+			fmt.Printf("   • Profile fields reset:   %d\n", len(scrub.FieldsReset))
+			fmt.Printf("   • Connections removed:    %d\n", scrub.ConnectionsRemoved)
+		}
+		if cleanup.ClearNotes {
+			fmt.Printf("   • Notes cleared:          %d\n", len(report.Cleanup.NotesCleared))
+		}
+		if cleanup.RevokeApps {
+			fmt.Printf("   • Apps revoked:           %d\n", len(report.Cleanup.AppsRevoked))
+		}
 		fmt.Println(strings.Repeat("=", 70))
 	} else {
 		fmt.Println()
-		fmt.Println("Cleanup skipped. Friends and servers remain unchanged.")
+		fmt.Println("Cleanup skipped. Friends, servers and your profile remain unchanged.")
+	}
+
+	writeReport(report, purgeOptions.ReportPath)
+}
+
+// clearTraces clears user notes and revokes authorized apps as chosen,
+// recording the results in report.
+func clearTraces(client *DiscordClient, choice CleanupChoice, report *CleanupReport, dryRun bool) {
+	notesHeading, appsHeading := "📝 Clearing user notes...", "🔑 Revoking authorized apps..."
+	if dryRun {
+		notesHeading, appsHeading = "📝 Checking user notes...", "🔑 Checking authorized apps..."
+	}
+
+	var err error
+	if choice.ClearNotes {
+		fmt.Println(notesHeading)
+		report.NotesCleared, err = client.ClearAllNotes(dryRun)
+		if err != nil {
+			fmt.Printf("❌ Error clearing notes: %v\n", err)
+		} else if dryRun {
+			fmt.Printf("✅ Would clear %d notes.\n", len(report.NotesCleared))
+		} else {
+			fmt.Printf("✅ Cleared %d notes.\n", len(report.NotesCleared))
+		}
+		fmt.Println()
+	}
+
+	if choice.RevokeApps && !client.halted() {
+		fmt.Println(appsHeading)
+		report.AppsRevoked, err = client.RevokeAllAuthorizedApps(dryRun)
+		if err != nil {
+			fmt.Printf("❌ Error revoking apps: %v\n", err)
+		} else if dryRun {
+			fmt.Printf("✅ Would revoke %d apps.\n", len(report.AppsRevoked))
+		} else {
+			fmt.Printf("✅ Revoked %d apps.\n", len(report.AppsRevoked))
+		}
+		fmt.Println()
+	}
+}
+
+// previewTraceCleanup lists the notes and authorized apps the cleanup would
+// clear, for dry runs. Without a profile choice both are listed, since
+// listing changes nothing.
+func previewTraceCleanup(client *DiscordClient, profile *Profile) *CleanupReport {
+	choice := CleanupChoice{ClearNotes: true, RevokeApps: true}
+	if profile != nil && profile.Cleanup != nil {
+		choice = *profile.Cleanup
+	}
+	if !choice.ClearNotes && !choice.RevokeApps {
+		return nil
+	}
+	fmt.Println()
+	report := &CleanupReport{DryRun: true}
+	clearTraces(client, choice, report, true)
+	return report
+}
+
+// restoreProfile applies a profile backup written by a scrub and returns the
+// process exit code.
+func restoreProfile(client *DiscordClient, path string) int {
+	backup, err := LoadAccountProfile(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("🪪 Restoring the profile saved on %s...\n", backup.SavedAt.Local().Format("2006-01-02 15:04"))
+	if err := client.RestoreProfile(backup); err != nil {
+		if client.halted() {
+			printSafetyHalt(client.out, client.halt)
+			return 2
+		}
+		fmt.Printf("❌ %v\n", err)
+		return 1
 	}
+	fmt.Println("✅ Profile restored.")
+	return 0
+}
+
+// writeReport saves the run report when a report path is configured.
+func writeReport(report *RunReport, path string) {
+	if path == "" {
+		return
+	}
+	if err := report.Write(path); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return
+	}
+	fmt.Printf("📝 Report written to %s\n", path)
 }
 
 func promptForToken() string {
@@ -1978,25 +4313,76 @@ func confirmDeletion() bool {
 	return response == "yes" || response == "y"
 }
 
-func confirmCleanup() bool {
+// promptResumeCheckpoint offers to resume from a checkpoint left by a halted
+// run for the same account (and moderator mode target). It returns nil when
+// starting fresh.
+func promptResumeCheckpoint(userID string, options PurgeOptions) *Checkpoint {
+	cp, err := LoadCheckpoint(checkpointPath(options))
+	if err != nil {
+		fmt.Printf("⚠️  Ignoring unreadable checkpoint: %v\n", err)
+		return nil
+	}
+	if cp == nil || cp.UserID != userID || cp.TargetAuthorID != options.TargetAuthorID {
+		return nil
+	}
+
+	fmt.Printf("💾 Found a checkpoint from %s (stopped during Phase %s).\n", cp.SavedAt.Local().Format("2006-01-02 15:04"), cp.Phase)
+	if cp.HaltReason != "" {
+		fmt.Printf("   Reason: %s\n", cp.HaltReason)
+	}
+	fmt.Printf("   %d servers and %d DM channels were already completed.\n", len(cp.CompletedGuilds), len(cp.CompletedDMChannels))
+	fmt.Print("Resume and skip completed items? (yes/no): ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	fmt.Println()
+
+	if response == "yes" || response == "y" {
+		return cp
+	}
+	return nil
+}
+
+// confirmProfileScrub asks whether to reset the account's public profile.
+func confirmProfileScrub() bool {
+	fmt.Println("Your profile can also be scrubbed: display name, bio, pronouns, avatar,")
+	fmt.Println("banner and custom status are reset and linked connections are removed.")
+	fmt.Println("The current values are saved to a local JSON file first.")
+	fmt.Print("Scrub your profile? (yes/no): ")
+
+	response := strings.TrimSpace(strings.ToLower(readInputLine()))
+	return response == "yes" || response == "y"
+}
+
+// confirmTraceCleanup asks whether to clear user notes and revoke authorized
+// applications.
+func confirmTraceCleanup() bool {
+	fmt.Println("Private notes you wrote on other users can be cleared, and every")
+	fmt.Println("third-party app you authorized can have its access revoked.")
+	fmt.Print("Clear notes and revoke authorized apps? (yes/no): ")
+
+	response := strings.TrimSpace(strings.ToLower(readInputLine()))
+	return response == "yes" || response == "y"
+}
+
+func confirmServerLeave() bool {
 	fmt.Println("╔══════════════════════════════════════════════════════╗")
 	fmt.Println("║  ⚠️  ADDITIONAL CLEANUP OPTION                      ║")
 	fmt.Println("╠══════════════════════════════════════════════════════╣")
 	fmt.Println("║                                                     ║")
 	fmt.Println("║  Would you like to also:                            ║")
 	fmt.Println("║                                                     ║")
-	fmt.Println("║    • Remove ALL friends from your friend list      ║")
-	fmt.Println("║    • Leave ALL servers you are a member of         ║")
+	fmt.Println("║    • Leave the servers you purged (listed first)   ║")
 	fmt.Println("║                                                     ║")
 	fmt.Println("║  This action CANNOT be undone!                      ║")
 	fmt.Println("║                                                     ║")
 	fmt.Println("╚══════════════════════════════════════════════════════╝")
 	fmt.Println()
-	fmt.Print("Remove all friends and leave all servers? (yes/no): ")
+	fmt.Print("Leave the servers you purged? (yes/no): ")
 
-	reader := bufio.NewReader(os.Stdin)
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
+	// Runtime controls may have taken over stdin during the purge.
+	response := strings.TrimSpace(strings.ToLower(readInputLine()))
 
 	return response == "yes" || response == "y"
 }
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnowflakeTimeRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 15, 10, 30, 0, 123*int(time.Millisecond), time.UTC)
+	id := snowflakeFromTime(at)
+	if got := snowflakeTime(id); !got.Equal(at) {
+		t.Errorf("snowflakeTime(%s) = %s, want %s", id, got, at)
+	}
+	if got := snowflakeFromTime(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)); got != "0" {
+		t.Errorf("snowflake before the Discord epoch = %s, want 0", got)
+	}
+}
+
+func TestSearchBounds(t *testing.T) {
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		options PurgeOptions
+		want    string
+	}{
+		{"no filters", PurgeOptions{}, ""},
+		{"before", PurgeOptions{Before: before}, "&max_id=" + snowflakeFromTime(before)},
+		{"after", PurgeOptions{After: after}, "&min_id=" + snowflakeFromTime(after)},
+		{"both", PurgeOptions{Before: before, After: after}, "&max_id=" + snowflakeFromTime(before) + "&min_id=" + snowflakeFromTime(after)},
+	}
+	for _, tt := range tests {
+		if got := searchBoundsQuery(tt.options.searchBounds()); got != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMessageInRange(t *testing.T) {
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	options := PurgeOptions{Before: before, After: after}
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"inside", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{"at after (inclusive)", after, true},
+		{"just before after", after.Add(-time.Millisecond), false},
+		{"at before (exclusive)", before, false},
+		{"just before before", before.Add(-time.Millisecond), true},
+	}
+	for _, tt := range tests {
+		if got := options.messageInRange(snowflakeFromTime(tt.at)); got != tt.want {
+			t.Errorf("%s: in range = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !(PurgeOptions{}).messageInRange(snowflakeFromTime(before)) {
+		t.Error("a message is out of range without any date filter")
+	}
+}
+
+func TestRetentionTrackerKeepLast(t *testing.T) {
+	tracker := newRetentionTracker(RetentionRule{KeepLast: 2})
+	// Newest first, interleaving two channels.
+	steps := []struct {
+		channel, message string
+		want             bool
+	}{
+		{"a", "105", true},
+		{"b", "205", true},
+		{"a", "104", true},
+		{"a", "103", false},
+		{"b", "204", true},
+		{"a", "105", true}, // seen again on a later page: still kept, counted once
+		{"b", "203", false},
+	}
+	for _, step := range steps {
+		if got := tracker.keeps(step.channel, step.message); got != step.want {
+			t.Errorf("keeps(%s, %s) = %v, want %v", step.channel, step.message, got, step.want)
+		}
+	}
+	if tracker.kept != 4 {
+		t.Errorf("kept = %d, want 4", tracker.kept)
+	}
+}
+
+func TestRetentionTrackerKeepDays(t *testing.T) {
+	tracker := newRetentionTracker(RetentionRule{KeepDays: 7})
+	recent := snowflakeFromTime(time.Now().Add(-24 * time.Hour))
+	old := snowflakeFromTime(time.Now().AddDate(0, 0, -30))
+	if !tracker.keeps("a", recent) {
+		t.Error("a message from yesterday is not kept")
+	}
+	if tracker.keeps("a", old) {
+		t.Error("a month-old message is kept")
+	}
+
+	// With both limits a message is kept if either keeps it.
+	both := newRetentionTracker(RetentionRule{KeepLast: 1, KeepDays: 7})
+	if !both.keeps("a", old) {
+		t.Error("the newest message is not kept by keep_last")
+	}
+	if both.keeps("a", snowflakeFromTime(time.Now().AddDate(0, 0, -31))) {
+		t.Error("an old second message is kept")
+	}
+
+	if newRetentionTracker(RetentionRule{}).keeps("a", recent) {
+		t.Error("an inactive rule keeps messages")
+	}
+}
+
+func TestChannelAllowed(t *testing.T) {
+	const guild = "1"
+	// Ancestry is the channel, then its parent channel, then its category.
+	thread := []string{"30", "20", "10"}
+	channel := []string{"20", "10"}
+	other := []string{"21", "11"}
+
+	tests := []struct {
+		name     string
+		options  PurgeOptions
+		ancestry []string
+		want     bool
+	}{
+		{"no rules", PurgeOptions{}, thread, true},
+		{"excluded channel", PurgeOptions{ExcludedChannelIDs: map[string]bool{"20": true}}, channel, false},
+		{"thread of excluded channel", PurgeOptions{ExcludedChannelIDs: map[string]bool{"20": true}}, thread, false},
+		{"excluded thread only", PurgeOptions{ExcludedChannelIDs: map[string]bool{"30": true}}, channel, true},
+		{"excluded category", PurgeOptions{ExcludedCategoryIDs: map[string]bool{"10": true}}, thread, false},
+		{"other category excluded", PurgeOptions{ExcludedCategoryIDs: map[string]bool{"10": true}}, other, true},
+		{"whole guild included", PurgeOptions{IncludedGuildIDs: map[string]bool{guild: true}}, other, true},
+		{"other guild included", PurgeOptions{IncludedGuildIDs: map[string]bool{"2": true}}, channel, false},
+		{"included category", PurgeOptions{IncludedChannelIDs: map[string]bool{"10": true}}, thread, true},
+		{"included channel", PurgeOptions{IncludedChannelIDs: map[string]bool{"20": true}}, thread, true},
+		{"not included", PurgeOptions{IncludedChannelIDs: map[string]bool{"20": true}}, other, false},
+		{
+			"exclusion beats inclusion",
+			PurgeOptions{IncludedChannelIDs: map[string]bool{"10": true}, ExcludedChannelIDs: map[string]bool{"20": true}},
+			thread,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.options.channelAllowed(guild, tt.ancestry); got != tt.want {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReactionPlacedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		reaction Reaction
+		want     []int
+	}{
+		{"not placed", Reaction{Count: 3}, nil},
+		{"normal", Reaction{Me: true}, []int{ReactionTypeNormal}},
+		{"super", Reaction{MeBurst: true}, []int{ReactionTypeBurst}},
+		{"both", Reaction{Me: true, MeBurst: true}, []int{ReactionTypeNormal, ReactionTypeBurst}},
+	}
+	for _, tt := range tests {
+		if got := tt.reaction.placedTypes(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: placed types = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateBotOnlyWhenAsked(t *testing.T) {
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bot token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":"123456789012345678","username":"helper","bot":true}`))
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		token   string
+		bot     bool
+		wantErr bool
+	}{
+		{token: "token", wantErr: true},
+		{token: "Bot token"},
+		{token: "token", bot: true},
+	} {
+		auths = nil
+		client := NewDiscordClient(tt.token)
+		client.baseURL = server.URL
+		client.bot = tt.bot
+		err := client.Authenticate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("token %q (bot %v): error = %v, want error %v", tt.token, tt.bot, err, tt.wantErr)
+		}
+		if len(auths) != 1 {
+			t.Errorf("token %q (bot %v): %d requests, want 1", tt.token, tt.bot, len(auths))
+		}
+		if !tt.wantErr && !client.bot {
+			t.Errorf("token %q (bot %v): not recognised as a bot", tt.token, tt.bot)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Moderator mode
+// =============================================================================
+
+// Permission bits used to check moderator access.
+const (
+	PermissionAdministrator  uint64 = 1 << 3
+	PermissionViewChannel    uint64 = 1 << 10
+	PermissionManageMessages uint64 = 1 << 13
+)
+
+// moderatePermissions are needed in a channel to delete other people's
+// messages there.
+const moderatePermissions = PermissionViewChannel | PermissionManageMessages
+
+// Permission overwrite types
+const (
+	OverwriteRole   = 0
+	OverwriteMember = 1
+)
+
+type Role struct {
+	ID          string `json:"id"`
+	Permissions string `json:"permissions"`
+}
+
+// GuildDetails is the part of a full guild object needed for permissions.
+type GuildDetails struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id"`
+	Roles   []Role `json:"roles"`
+}
+
+// GuildMember is the account's member record in a guild: its roles and its
+// per-server profile.
+type GuildMember struct {
+	Roles  []string `json:"roles"`
+	Nick   *string  `json:"nick"`
+	Avatar *string  `json:"avatar"`
+	Banner *string  `json:"banner"`
+	Bio    *string  `json:"bio"`
+}
+
+// GetGuildDetails fetches a guild's owner and roles.
+func (c *DiscordClient) GetGuildDetails(guildID string) (*GuildDetails, error) {
+	body, status, err := c.request("GET", fmt.Sprintf("/guilds/%s", guildID))
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("HTTP %d", status)
+	}
+
+	var guild GuildDetails
+	if err := json.Unmarshal(body, &guild); err != nil {
+		return nil, fmt.Errorf("parsing guild: %w", err)
+	}
+	return &guild, nil
+}
+
+// GetOwnGuildMember fetches the account's own member record in a guild. Bots
+// can't use the @me member route and look themselves up instead.
+func (c *DiscordClient) GetOwnGuildMember(guildID string) (*GuildMember, error) {
+	path := fmt.Sprintf("/users/@me/guilds/%s/member", guildID)
+	if c.bot {
+		path = fmt.Sprintf("/guilds/%s/members/%s", guildID, c.userID)
+	}
+	body, status, err := c.request("GET", path)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("HTTP %d", status)
+	}
+
+	var member GuildMember
+	if err := json.Unmarshal(body, &member); err != nil {
+		return nil, fmt.Errorf("parsing guild member: %w", err)
+	}
+	return &member, nil
+}
+
+func parsePermissions(value string) uint64 {
+	perms, _ := strconv.ParseUint(value, 10, 64)
+	return perms
+}
+
+// basePermissions computes the account's guild-wide permissions from the
+// @everyone role and its own roles.
+func basePermissions(guild *GuildDetails, member *GuildMember, userID string) uint64 {
+	if guild.OwnerID == userID {
+		return ^uint64(0)
+	}
+	memberRoles := make(map[string]bool, len(member.Roles))
+	for _, id := range member.Roles {
+		memberRoles[id] = true
+	}
+
+	var perms uint64
+	for _, role := range guild.Roles {
+		// The @everyone role shares the guild's ID.
+		if role.ID == guild.ID || memberRoles[role.ID] {
+			perms |= parsePermissions(role.Permissions)
+		}
+	}
+	if perms&PermissionAdministrator != 0 {
+		return ^uint64(0)
+	}
+	return perms
+}
+
+// channelPermissions applies a channel's overwrites to the base permissions
+// in Discord's order: @everyone, then the member's roles combined, then the
+// member itself.
+func channelPermissions(base uint64, guild *GuildDetails, member *GuildMember, userID string, ch Channel) uint64 {
+	if base&PermissionAdministrator != 0 {
+		return base
+	}
+	memberRoles := make(map[string]bool, len(member.Roles))
+	for _, id := range member.Roles {
+		memberRoles[id] = true
+	}
+
+	perms := base
+	var roleAllow, roleDeny uint64
+	var memberOverwrite *PermissionOverwrite
+	for i, overwrite := range ch.PermissionOverwrites {
+		switch {
+		case overwrite.Type == OverwriteRole && overwrite.ID == guild.ID:
+			perms &^= parsePermissions(overwrite.Deny)
+			perms |= parsePermissions(overwrite.Allow)
+		case overwrite.Type == OverwriteRole && memberRoles[overwrite.ID]:
+			roleAllow |= parsePermissions(overwrite.Allow)
+			roleDeny |= parsePermissions(overwrite.Deny)
+		case overwrite.Type == OverwriteMember && overwrite.ID == userID:
+			memberOverwrite = &ch.PermissionOverwrites[i]
+		}
+	}
+	perms &^= roleDeny
+	perms |= roleAllow
+	if memberOverwrite != nil {
+		perms &^= parsePermissions(memberOverwrite.Deny)
+		perms |= parsePermissions(memberOverwrite.Allow)
+	}
+	return perms
+}
+
+// moderatedChannels returns the guild's message channels split by whether
+// the account can delete other people's messages there. Threads follow their
+// parent channel and are covered by the scope rules.
+func (c *DiscordClient) moderatedChannels(guildID string) (allowed, denied []string, err error) {
+	guild, err := c.GetGuildDetails(guildID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching roles: %w", err)
+	}
+	member, err := c.GetOwnGuildMember(guildID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching your roles: %w", err)
+	}
+	channels, err := c.GetGuildChannels(guildID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching channels: %w", err)
+	}
+
+	base := basePermissions(guild, member, c.userID)
+	for _, ch := range channels {
+		switch ch.Type {
+		case ChannelTypeGuildText, ChannelTypeGuildNews, ChannelTypeGuildVoice, ChannelTypeGuildStageVoice,
+			ChannelTypeGuildForum, ChannelTypeGuildMedia:
+		default:
+			continue
+		}
+		if channelPermissions(base, guild, member, c.userID, ch)&moderatePermissions == moderatePermissions {
+			allowed = append(allowed, ch.ID)
+		} else {
+			denied = append(denied, ch.ID)
+		}
+	}
+	return allowed, denied, nil
+}
+
+// moderationOptions turns options into a moderator run against authorID:
+// only Phase 1 runs, and only in the listed servers. Preservation and
+// retention rules describe which of your own messages to keep, so they don't
+// carry over to the target's messages.
+func moderationOptions(options PurgeOptions, authorID string, guildIDs []string) PurgeOptions {
+	options.TargetAuthorID = authorID
+	options.IncludedGuildIDs = idSet(guildIDs)
+	options.IncludedDMChannelIDs = nil
+	options.IncludedChannelIDs = nil
+	options.Phases = map[string]bool{"1": true}
+	options.LeaveThreads = false
+	options.Preserve = PreserveRules{}
+	options.Retention = RetentionRule{}
+	options.GuildRetention = nil
+	return options
+}
+
+// checkModeratorAccess verifies Manage Messages in every selected server.
+// Servers where no channel grants it are dropped from the scope, and
+// channels without it are excluded so their messages aren't attempted. It
+// returns false when no server is left.
+func (c *DiscordClient) checkModeratorAccess(guilds []Guild, options *PurgeOptions) bool {
+	fmt.Fprintln(c.out, "🛡️  Checking Manage Messages permission...")
+
+	if options.ExcludedChannelIDs == nil {
+		options.ExcludedChannelIDs = make(map[string]bool)
+	}
+	member := make(map[string]bool, len(guilds))
+	for _, guild := range guilds {
+		member[guild.ID] = true
+	}
+	for guildID := range options.IncludedGuildIDs {
+		if !member[guildID] {
+			fmt.Fprintf(c.out, "   ❌ %s: not a server you are a member of — skipped\n", guildID)
+			delete(options.IncludedGuildIDs, guildID)
+		}
+	}
+
+	for _, guild := range guilds {
+		if !options.IncludedGuildIDs[guild.ID] || c.halted() {
+			continue
+		}
+		guildID, name := guild.ID, displayGuildName(guild)
+
+		allowed, denied, err := c.moderatedChannels(guildID)
+		c.pacer.Wait(paceDiscovery)
+		if err != nil {
+			fmt.Fprintf(c.out, "   ❌ %s: %v — skipped\n", name, err)
+			delete(options.IncludedGuildIDs, guildID)
+			continue
+		}
+		if len(allowed) == 0 {
+			fmt.Fprintf(c.out, "   ❌ %s: you don't have Manage Messages — skipped\n", name)
+			delete(options.IncludedGuildIDs, guildID)
+			continue
+		}
+
+		for _, id := range denied {
+			options.ExcludedChannelIDs[id] = true
+		}
+		if len(denied) > 0 {
+			fmt.Fprintf(c.out, "   ✅ %s: Manage Messages in %d of %d channels (the rest are skipped)\n", name, len(allowed), len(allowed)+len(denied))
+		} else {
+			fmt.Fprintf(c.out, "   ✅ %s: Manage Messages in all %d channels\n", name, len(allowed))
+		}
+	}
+	fmt.Fprintln(c.out)
+
+	return len(options.IncludedGuildIDs) > 0 && !c.halted()
+}
+
+// confirmModeration asks before deleting another user's messages.
+func confirmModeration(authorID string, guildCount int) bool {
+	fmt.Println("╔══════════════════════════════════════════════════════╗")
+	fmt.Println("║  ⚠️  WARNING — MODERATOR PURGE                      ║")
+	fmt.Println("╚══════════════════════════════════════════════════════╝")
+	fmt.Println()
+	fmt.Printf("Delete ALL messages by user %s in %d servers? This CANNOT be undone. (yes/no): ", authorID, guildCount)
+
+	response := strings.TrimSpace(strings.ToLower(readInputLine()))
+	return response == "yes" || response == "y"
+}
+
+// splitIDList splits a comma-separated list of IDs.
+func splitIDList(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
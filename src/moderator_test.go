@@ -0,0 +1,165 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func permString(perms uint64) string {
+	return strconv.FormatUint(perms, 10)
+}
+
+func TestChannelPermissions(t *testing.T) {
+	const (
+		guildID = "100"
+		userID  = "200"
+		modRole = "300"
+		subRole = "301"
+	)
+	guild := &GuildDetails{
+		ID:      guildID,
+		OwnerID: "999",
+		Roles: []Role{
+			{ID: guildID, Permissions: permString(PermissionViewChannel)},
+			{ID: modRole, Permissions: permString(PermissionManageMessages)},
+			{ID: subRole, Permissions: "0"},
+			{ID: "302", Permissions: permString(PermissionAdministrator)},
+		},
+	}
+	denyEveryone := PermissionOverwrite{ID: guildID, Type: OverwriteRole, Deny: permString(moderatePermissions)}
+
+	tests := []struct {
+		name       string
+		owner      string
+		roles      []string
+		overwrites []PermissionOverwrite
+		want       bool
+	}{
+		{name: "no roles", want: false},
+		{name: "role grants manage messages", roles: []string{modRole}, want: true},
+		{name: "owner", owner: userID, overwrites: []PermissionOverwrite{denyEveryone}, want: true},
+		{name: "administrator role", roles: []string{"302"}, overwrites: []PermissionOverwrite{denyEveryone}, want: true},
+		{
+			name:       "everyone deny",
+			roles:      []string{modRole},
+			overwrites: []PermissionOverwrite{denyEveryone},
+			want:       false,
+		},
+		{
+			name:  "role allow overrides everyone deny",
+			roles: []string{subRole},
+			overwrites: []PermissionOverwrite{
+				denyEveryone,
+				{ID: subRole, Type: OverwriteRole, Allow: permString(moderatePermissions)},
+			},
+			want: true,
+		},
+		{
+			name:  "role allow beats another role's deny",
+			roles: []string{modRole, subRole},
+			overwrites: []PermissionOverwrite{
+				{ID: modRole, Type: OverwriteRole, Deny: permString(PermissionManageMessages)},
+				{ID: subRole, Type: OverwriteRole, Allow: permString(PermissionManageMessages)},
+			},
+			want: true,
+		},
+		{
+			name:  "overwrite for a role the member lacks",
+			roles: []string{modRole},
+			overwrites: []PermissionOverwrite{
+				{ID: subRole, Type: OverwriteRole, Deny: permString(PermissionManageMessages)},
+			},
+			want: true,
+		},
+		{
+			name:  "member deny overrides role allow",
+			roles: []string{subRole},
+			overwrites: []PermissionOverwrite{
+				{ID: subRole, Type: OverwriteRole, Allow: permString(moderatePermissions)},
+				{ID: userID, Type: OverwriteMember, Deny: permString(PermissionManageMessages)},
+			},
+			want: false,
+		},
+		{
+			name:  "member allow overrides role deny",
+			roles: []string{modRole},
+			overwrites: []PermissionOverwrite{
+				{ID: modRole, Type: OverwriteRole, Deny: permString(PermissionManageMessages)},
+				{ID: userID, Type: OverwriteMember, Allow: permString(PermissionManageMessages)},
+			},
+			want: true,
+		},
+		{
+			name:  "overwrite for another member",
+			roles: []string{modRole},
+			overwrites: []PermissionOverwrite{
+				{ID: "201", Type: OverwriteMember, Deny: permString(PermissionManageMessages)},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := *guild
+			if tt.owner != "" {
+				g.OwnerID = tt.owner
+			}
+			member := &GuildMember{Roles: tt.roles}
+			base := basePermissions(&g, member, userID)
+			perms := channelPermissions(base, &g, member, userID, Channel{ID: "400", PermissionOverwrites: tt.overwrites})
+			if got := perms&moderatePermissions == moderatePermissions; got != tt.want {
+				t.Errorf("can moderate = %v, want %v (permissions %b)", got, tt.want, perms)
+			}
+		})
+	}
+}
+
+func TestBasePermissions(t *testing.T) {
+	guild := &GuildDetails{
+		ID:      "100",
+		OwnerID: "999",
+		Roles: []Role{
+			{ID: "100", Permissions: permString(PermissionViewChannel)},
+			{ID: "300", Permissions: permString(PermissionManageMessages)},
+			{ID: "302", Permissions: permString(PermissionAdministrator)},
+		},
+	}
+	tests := []struct {
+		name   string
+		userID string
+		roles  []string
+		want   uint64
+	}{
+		{"everyone only", "200", nil, PermissionViewChannel},
+		{"roles combine", "200", []string{"300"}, PermissionViewChannel | PermissionManageMessages},
+		{"unknown role", "200", []string{"555"}, PermissionViewChannel},
+		{"administrator", "200", []string{"302"}, ^uint64(0)},
+		{"owner", "999", nil, ^uint64(0)},
+	}
+	for _, tt := range tests {
+		if got := basePermissions(guild, &GuildMember{Roles: tt.roles}, tt.userID); got != tt.want {
+			t.Errorf("%s: base permissions = %b, want %b", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestModerationOptionsDropsKeepRules(t *testing.T) {
+	options := PurgeOptions{
+		Preserve:           PreserveRules{Pinned: true, MinReactions: 3, ReactionEmojis: []string{"⭐"}},
+		Retention:          RetentionRule{KeepLast: 10, KeepDays: 30},
+		GuildRetention:     map[string]RetentionRule{"100": {KeepLast: 5}},
+		IncludedChannelIDs: map[string]bool{"400": true},
+	}
+	got := moderationOptions(options, "555", []string{"100"})
+
+	if !reflect.DeepEqual(got.Preserve, PreserveRules{}) {
+		t.Errorf("preserve rules kept: %+v", got.Preserve)
+	}
+	if got.Retention.active() || got.GuildRetention != nil || got.retentionFor("100").active() {
+		t.Errorf("retention kept: %+v, %+v", got.Retention, got.GuildRetention)
+	}
+	if got.TargetAuthorID != "555" || !got.IncludedGuildIDs["100"] || got.IncludedChannelIDs != nil {
+		t.Errorf("scope = author %s, guilds %v, channels %v", got.TargetAuthorID, got.IncludedGuildIDs, got.IncludedChannelIDs)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Adaptive pacing
+// =============================================================================
+
+// Request classes paced independently of each other.
+const (
+	paceSearch    = "search"
+	paceDelete    = "delete"
+	paceReaction  = "reaction"
+	paceDiscovery = "discovery"
+)
+
+const (
+	pacingFile = "discord-purge-pacing.json"
+
+	defaultPaceDelay = 350 * time.Millisecond
+	minPaceDelay     = 150 * time.Millisecond
+	maxPaceDelay     = 15 * time.Second
+
+	// After this many consecutive successes a class speeds up by paceSpeedup.
+	paceSpeedupEvery = 25
+	paceSpeedup      = 0.9
+	// On a 429 the class delay is multiplied by paceBackoff.
+	paceBackoff = 2.0
+)
+
+var paceClasses = []string{paceSearch, paceDelete, paceReaction, paceDiscovery}
+
+// classPace is the learned state for one request class.
+type classPace struct {
+	delay      time.Duration
+	streak     int
+	requests   int
+	rateLimits int
+	first      time.Time
+	last       time.Time
+}
+
+// Pacer tunes the delay between requests of each class from observed 429s:
+// it speeds up gradually while requests succeed and backs off
+// multiplicatively when Discord rate limits a class.
+type Pacer struct {
+	mu      sync.Mutex
+	classes map[string]*classPace
+}
+
+func NewPacer() *Pacer {
+	p := &Pacer{classes: make(map[string]*classPace)}
+	for _, class := range paceClasses {
+		p.classes[class] = &classPace{delay: defaultPaceDelay}
+	}
+	return p
+}
+
+// classifyRequest maps an API call to its pacing class.
+func classifyRequest(method, path string) string {
+	switch {
+	case strings.Contains(path, "/messages/search"):
+		return paceSearch
+	case strings.Contains(path, "/reactions/"):
+		return paceReaction
+	case method == "DELETE" && strings.Contains(path, "/messages/"),
+		method == "POST" && strings.HasSuffix(path, "/messages/bulk-delete"):
+		return paceDelete
+	}
+	return paceDiscovery
+}
+
+// Wait sleeps for the current delay of class.
+func (p *Pacer) Wait(class string) {
+	time.Sleep(p.Delay(class))
+}
+
+// Delay returns the current delay of class.
+func (p *Pacer) Delay(class string) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.class(class).delay
+}
+
+// SetDelay overrides the current delay of class.
+func (p *Pacer) SetDelay(class string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.class(class).delay = clampPaceDelay(d)
+}
+
+// Observe records the outcome of one request of class.
+func (p *Pacer) Observe(class string, rateLimited bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cp := p.class(class)
+	now := time.Now()
+	if cp.first.IsZero() {
+		cp.first = now
+	}
+	cp.last = now
+	cp.requests++
+
+	if rateLimited {
+		cp.rateLimits++
+		cp.streak = 0
+		cp.delay = clampPaceDelay(time.Duration(float64(cp.delay) * paceBackoff))
+		return
+	}
+
+	cp.streak++
+	if cp.streak >= paceSpeedupEvery {
+		cp.streak = 0
+		cp.delay = clampPaceDelay(time.Duration(float64(cp.delay) * paceSpeedup))
+	}
+}
+
+func (p *Pacer) class(class string) *classPace {
+	cp, ok := p.classes[class]
+	if !ok {
+		cp = &classPace{delay: defaultPaceDelay}
+		p.classes[class] = cp
+	}
+	return cp
+}
+
+func clampPaceDelay(d time.Duration) time.Duration {
+	if d < minPaceDelay {
+		return minPaceDelay
+	}
+	if d > maxPaceDelay {
+		return maxPaceDelay
+	}
+	return d
+}
+
+// Load restores learned delays from path. A missing file is not an error.
+func (p *Pacer) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading pacing file: %w", err)
+	}
+
+	var saved map[string]int64
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("parsing pacing file: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for class, ms := range saved {
+		p.class(class).delay = clampPaceDelay(time.Duration(ms) * time.Millisecond)
+	}
+	return nil
+}
+
+// Save writes the learned delays (in milliseconds) to path.
+func (p *Pacer) Save(path string) error {
+	p.mu.Lock()
+	saved := make(map[string]int64, len(p.classes))
+	for class, cp := range p.classes {
+		saved[class] = cp.delay.Milliseconds()
+	}
+	p.mu.Unlock()
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding pacing file: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing pacing file: %w", err)
+	}
+	return nil
+}
+
+// PrintReport prints the learned delay and effective throughput per class.
+func (p *Pacer) PrintReport(w io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	classes := make([]string, 0, len(p.classes))
+	for class := range p.classes {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	fmt.Fprintln(w, "🚦 PACING AND THROUGHPUT:")
+	for _, class := range classes {
+		cp := p.classes[class]
+		if cp.requests == 0 {
+			continue
+		}
+		perMinute := 0.0
+		if span := cp.last.Sub(cp.first); span > 0 {
+			perMinute = float64(cp.requests-1) / span.Minutes()
+		}
+		fmt.Fprintf(w, "   %-10s %6d requests  %7.1f/min  delay now %-6s %d rate limits\n",
+			class, cp.requests, perMinute, cp.delay.Round(time.Millisecond), cp.rateLimits)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPacerSpeedsUpAfterSuccesses(t *testing.T) {
+	p := NewPacer()
+	for i := 0; i < paceSpeedupEvery-1; i++ {
+		p.Observe(paceDelete, false)
+	}
+	if got := p.Delay(paceDelete); got != defaultPaceDelay {
+		t.Fatalf("delay after %d successes = %s, want %s", paceSpeedupEvery-1, got, defaultPaceDelay)
+	}
+	p.Observe(paceDelete, false)
+	want := time.Duration(float64(defaultPaceDelay) * paceSpeedup)
+	if got := p.Delay(paceDelete); got != want {
+		t.Fatalf("delay after %d successes = %s, want %s", paceSpeedupEvery, got, want)
+	}
+	if got := p.Delay(paceSearch); got != defaultPaceDelay {
+		t.Errorf("search delay = %s, want %s (classes are paced independently)", got, defaultPaceDelay)
+	}
+
+	for i := 0; i < 100*paceSpeedupEvery; i++ {
+		p.Observe(paceDelete, false)
+	}
+	if got := p.Delay(paceDelete); got != minPaceDelay {
+		t.Errorf("delay after many successes = %s, want the minimum %s", got, minPaceDelay)
+	}
+}
+
+func TestPacerBacksOffOnRateLimit(t *testing.T) {
+	p := NewPacer()
+	for i := 0; i < paceSpeedupEvery-1; i++ {
+		p.Observe(paceSearch, false)
+	}
+	p.Observe(paceSearch, true)
+	want := time.Duration(float64(defaultPaceDelay) * paceBackoff)
+	if got := p.Delay(paceSearch); got != want {
+		t.Fatalf("delay after a 429 = %s, want %s", got, want)
+	}
+
+	// The 429 reset the streak, so one more success doesn't speed up.
+	p.Observe(paceSearch, false)
+	if got := p.Delay(paceSearch); got != want {
+		t.Errorf("delay after a 429 and one success = %s, want %s", got, want)
+	}
+
+	for i := 0; i < 20; i++ {
+		p.Observe(paceSearch, true)
+	}
+	if got := p.Delay(paceSearch); got != maxPaceDelay {
+		t.Errorf("delay after repeated 429s = %s, want the maximum %s", got, maxPaceDelay)
+	}
+}
+
+func TestClassifyRequest(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/guilds/1/messages/search?author_id=2", paceSearch},
+		{"DELETE", "/channels/1/messages/2", paceDelete},
+		{"POST", "/channels/1/messages/bulk-delete", paceDelete},
+		{"DELETE", "/channels/1/messages/2/reactions/%F0%9F%91%8D/@me", paceReaction},
+		{"GET", "/channels/1/messages?limit=100", paceDiscovery},
+		{"DELETE", "/users/@me/guilds/1", paceDiscovery},
+	}
+	for _, tt := range tests {
+		if got := classifyRequest(tt.method, tt.path); got != tt.want {
+			t.Errorf("classifyRequest(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
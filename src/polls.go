@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// Poll votes
+// =============================================================================
+
+// Poll is a message's poll. Results is missing until Discord has counted the
+// votes.
+type Poll struct {
+	Question         PollMedia    `json:"question"`
+	Answers          []PollAnswer `json:"answers"`
+	Expiry           string       `json:"expiry"`
+	AllowMultiselect bool         `json:"allow_multiselect"`
+	Results          *PollResults `json:"results,omitempty"`
+}
+
+type PollMedia struct {
+	Text string `json:"text"`
+}
+
+type PollAnswer struct {
+	AnswerID  int       `json:"answer_id"`
+	PollMedia PollMedia `json:"poll_media"`
+}
+
+// PollResults holds the vote count per answer; MeVoted marks the answers the
+// current user voted for.
+type PollResults struct {
+	IsFinalized  bool              `json:"is_finalized"`
+	AnswerCounts []PollAnswerCount `json:"answer_counts"`
+}
+
+type PollAnswerCount struct {
+	ID      int  `json:"id"`
+	Count   int  `json:"count"`
+	MeVoted bool `json:"me_voted"`
+}
+
+// voted reports whether the current user voted in the poll.
+func (p *Poll) voted() bool {
+	if p == nil || p.Results == nil {
+		return false
+	}
+	for _, answer := range p.Results.AnswerCounts {
+		if answer.MeVoted {
+			return true
+		}
+	}
+	return false
+}
+
+// closed reports whether voting has ended, so votes can no longer change.
+func (p *Poll) closed(now time.Time) bool {
+	if p.Results != nil && p.Results.IsFinalized {
+		return true
+	}
+	if p.Expiry == "" {
+		return false
+	}
+	expiry, err := time.Parse(time.RFC3339Nano, p.Expiry)
+	return err == nil && !expiry.After(now)
+}
+
+// RemovePollVote withdraws the current user's votes from an open poll.
+func (c *DiscordClient) RemovePollVote(channelID, messageID string) error {
+	payload, _ := json.Marshal(map[string][]int{"answer_ids": {}})
+	body, status, err := c.requestWithBody("PUT", fmt.Sprintf("/channels/%s/polls/%s/answers/@me", channelID, messageID), string(payload))
+	if err != nil {
+		return err
+	}
+	if status == 200 || status == 204 || status == 404 {
+		return nil
+	}
+	if detail := formatAPIError(body); detail != "" {
+		return fmt.Errorf("HTTP %d, %s", status, detail)
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollClosed(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		poll Poll
+		want bool
+	}{
+		{"no expiry", Poll{}, false},
+		{"expires later", Poll{Expiry: "2025-06-02T12:00:00.000000+00:00"}, false},
+		{"expired", Poll{Expiry: "2025-05-31T12:00:00.000000+00:00"}, true},
+		{"expires now", Poll{Expiry: "2025-06-01T12:00:00Z"}, true},
+		{"finalized early", Poll{Expiry: "2025-06-02T12:00:00Z", Results: &PollResults{IsFinalized: true}}, true},
+		{"counted, not finalized", Poll{Expiry: "2025-06-02T12:00:00Z", Results: &PollResults{}}, false},
+		{"unparsable expiry", Poll{Expiry: "soon"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.poll.closed(now); got != tt.want {
+			t.Errorf("%s: closed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPollVoted(t *testing.T) {
+	var missing *Poll
+	if missing.voted() {
+		t.Error("a message without a poll reports a vote")
+	}
+	p := &Poll{Results: &PollResults{AnswerCounts: []PollAnswerCount{{ID: 1, Count: 3}, {ID: 2, Count: 1}}}}
+	if p.voted() {
+		t.Error("voted without any me_voted answer")
+	}
+	p.Results.AnswerCounts[1].MeVoted = true
+	if !p.voted() {
+		t.Error("not voted with a me_voted answer")
+	}
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Live run progress
+// =============================================================================
+
+// Phase states reported in RunStatus.
+const (
+	phasePending = "pending"
+	phaseRunning = "running"
+	phaseDone    = "done"
+	phaseSkipped = "skipped"
+	phaseStopped = "stopped"
+)
+
+// phaseLabels names every phase in the order PurgeAll runs them.
+var phaseLabels = []struct{ ID, Label string }{
+	{"1", "Server messages"},
+	{"2a", "Open DMs"},
+	{"2b", "Hidden DMs"},
+	{"2c", "Data package DMs"},
+	{"3", "Reactions"},
+	{"4", "Server profiles"},
+}
+
+// PhaseStatus is the progress of one phase. Done/Total count servers or
+// channels, and Skipped those abandoned on request; Work counts messages deleted (channels scanned in Phase 3, server
+// profiles reset in Phase 4) against the up-front Estimate.
+type PhaseStatus struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	State     string    `json:"state"`
+	Done      int       `json:"done"`
+	Skipped   int       `json:"skipped"`
+	Total     int       `json:"total"`
+	Work      int       `json:"work"`
+	Estimate  int       `json:"estimate"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// workUnit names what a phase's Work counts.
+func (ph PhaseStatus) workUnit() string {
+	switch ph.ID {
+	case "3":
+		return "channels"
+	case "4":
+		return "profiles"
+	}
+	return "messages"
+}
+
+// rate returns the phase's work per minute since it started.
+func (ph PhaseStatus) rate(now time.Time) float64 {
+	elapsed := now.Sub(ph.StartedAt).Minutes()
+	if ph.StartedAt.IsZero() || elapsed <= 0 || ph.Work == 0 {
+		return 0
+	}
+	return float64(ph.Work) / elapsed
+}
+
+// remaining returns the estimated work left in the phase.
+func (ph PhaseStatus) remaining() int {
+	if ph.State == phaseDone || ph.State == phaseSkipped || ph.Work >= ph.Estimate {
+		return 0
+	}
+	return ph.Estimate - ph.Work
+}
+
+// ETA estimates the time left from measured rates: each phase's own rate once
+// it has started, or the run's message rate for message phases still pending.
+// ok is false while there is not enough data.
+func (s RunStatus) ETA(now time.Time) (eta time.Duration, ok bool) {
+	messageRate := 0.0
+	if elapsed := now.Sub(s.StartedAt).Minutes(); elapsed > 0 {
+		messageRate = float64(s.MessagesDeleted) / elapsed
+	}
+	for _, ph := range s.Phases {
+		left := ph.remaining()
+		if left == 0 {
+			continue
+		}
+		rate := ph.rate(now)
+		if rate == 0 && ph.State == phasePending && ph.workUnit() == "messages" {
+			rate = messageRate
+		}
+		if rate <= 0 {
+			return 0, false
+		}
+		eta += time.Duration(float64(left) / rate * float64(time.Minute))
+	}
+	return eta, true
+}
+
+// RunStatus is a point-in-time view of a purge run, safe to hand to other
+// goroutines (the web UI polls it while PurgeAll runs).
+type RunStatus struct {
+	Running             bool          `json:"running"`
+	Finished            bool          `json:"finished"`
+	StartedAt           time.Time     `json:"started_at"`
+	FinishedAt          time.Time     `json:"finished_at"`
+	Phases              []PhaseStatus `json:"phases"`
+	CurrentPhase        string        `json:"current_phase"`
+	CurrentItem         string        `json:"current_item"`
+	MessagesDeleted     int           `json:"messages_deleted"`
+	ReactionsRemoved    int           `json:"reactions_removed"`
+	RateLimits          int           `json:"rate_limits"`
+	RateLimitRoute      string        `json:"rate_limit_route,omitempty"`
+	RateLimitedUntil    time.Time     `json:"rate_limited_until"`
+	Estimated           bool          `json:"estimated"`
+	Halted              bool          `json:"halted"`
+	HaltReason          string        `json:"halt_reason,omitempty"`
+	DMChannelsProcessed int           `json:"dm_channels_processed"`
+}
+
+// Progress tracks the live status of the client's current run.
+type Progress struct {
+	mu     sync.Mutex
+	status RunStatus
+}
+
+func NewProgress() *Progress {
+	return &Progress{}
+}
+
+// Snapshot returns a copy of the current status.
+func (p *Progress) Snapshot() RunStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.status
+	s.Phases = append([]PhaseStatus(nil), p.status.Phases...)
+	return s
+}
+
+// start resets the status for a new run.
+func (p *Progress) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = RunStatus{Running: true, StartedAt: time.Now()}
+	for _, phase := range phaseLabels {
+		p.status.Phases = append(p.status.Phases, PhaseStatus{ID: phase.ID, Label: phase.Label, State: phasePending})
+	}
+}
+
+// startPhase marks a phase as running with total items to process.
+func (p *Progress) startPhase(id string, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.CurrentPhase = id
+	p.status.CurrentItem = ""
+	if phase := p.phase(id); phase != nil {
+		phase.State = phaseRunning
+		phase.Total = total
+		phase.StartedAt = time.Now()
+	}
+}
+
+// setEstimate records the up-front estimate of a phase's work.
+func (p *Progress) setEstimate(id string, estimate int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Estimated = true
+	if phase := p.phase(id); phase != nil {
+		phase.Estimate = estimate
+	}
+}
+
+// addEstimate raises a phase's estimate by work discovered during the run.
+func (p *Progress) addEstimate(id string, extra int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if phase := p.phase(id); phase != nil {
+		phase.Estimate += extra
+	}
+}
+
+// endPhase records the final state of a phase (done or skipped).
+func (p *Progress) endPhase(id string, state string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if phase := p.phase(id); phase != nil {
+		phase.State = state
+	}
+	if p.status.CurrentPhase == id {
+		p.status.CurrentItem = ""
+	}
+}
+
+// startItem records the server or channel currently being processed.
+func (p *Progress) startItem(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.CurrentItem = name
+}
+
+// itemDone advances the current phase's item counter.
+func (p *Progress) itemDone() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if phase := p.phase(p.status.CurrentPhase); phase != nil {
+		phase.Done++
+	}
+}
+
+// itemSkipped counts an item of the current phase skipped on request.
+func (p *Progress) itemSkipped() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if phase := p.phase(p.status.CurrentPhase); phase != nil {
+		phase.Skipped++
+	}
+}
+
+func (p *Progress) messageDeleted() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.MessagesDeleted++
+	if phase := p.phase(p.status.CurrentPhase); phase != nil && phase.workUnit() == "messages" {
+		phase.Work++
+	}
+}
+
+// channelScanned counts a channel scanned for reactions in Phase 3.
+func (p *Progress) channelScanned() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if phase := p.phase("3"); phase != nil {
+		phase.Work++
+	}
+}
+
+// identityReset counts a server profile reset in Phase 4.
+func (p *Progress) identityReset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if phase := p.phase("4"); phase != nil {
+		phase.Work++
+	}
+}
+
+func (p *Progress) reactionRemoved() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.ReactionsRemoved++
+}
+
+// rateLimited records a rate-limit wait on route.
+func (p *Progress) rateLimited(route string, wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.RateLimits++
+	p.status.RateLimitRoute = route
+	p.status.RateLimitedUntil = time.Now().Add(wait)
+}
+
+// finish records the final statistics of the run.
+func (p *Progress) finish(stats PurgeStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Running = false
+	p.status.Finished = true
+	p.status.FinishedAt = time.Now()
+	p.status.CurrentItem = ""
+	p.status.MessagesDeleted = stats.TotalMessagesDeleted
+	p.status.ReactionsRemoved = stats.TotalReactionsRemoved + stats.TotalSuperReactionsRemoved
+	p.status.DMChannelsProcessed = stats.DMChannelsProcessed
+	p.status.Halted = stats.Halted
+	p.status.HaltReason = stats.HaltReason
+	if phase := p.phase(p.status.CurrentPhase); phase != nil && stats.Halted {
+		phase.State = phaseStopped
+	}
+}
+
+func (p *Progress) phase(id string) *PhaseStatus {
+	for i := range p.status.Phases {
+		if p.status.Phases[i].ID == id {
+			return &p.status.Phases[i]
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// Live status line
+// =============================================================================
+
+const (
+	statusRefreshTTY   = time.Second
+	statusRefreshPlain = time.Minute
+	defaultStatusWidth = 120
+)
+
+// console serialises a run's output. The goroutines that print while a run
+// is going (keyboard and signal controls, the daemon's signal handler, the
+// web UI) write through the same console as the run, and an attached live
+// status line is cleared before each write and redrawn after it.
+type console struct {
+	mu     sync.Mutex
+	w      io.Writer
+	status *liveStatus
+}
+
+func newConsole(w io.Writer) *console {
+	return &console{w: w}
+}
+
+func (o *console) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.status == nil {
+		return o.w.Write(p)
+	}
+	o.status.clearLine()
+	n, err := o.w.Write(p)
+	if len(p) > 0 {
+		o.status.midLine = p[len(p)-1] != '\n'
+	}
+	o.status.drawLine()
+	return n, err
+}
+
+// liveStatus keeps a one-line progress summary on screen while a run prints
+// its log. On a terminal it attaches to the console, which clears the line
+// before each log write and redraws it after; otherwise the summary is
+// printed as an ordinary line every statusRefreshPlain.
+type liveStatus struct {
+	progress *Progress
+	console  *console
+	tty      bool
+	stop     chan struct{}
+	wg       sync.WaitGroup
+
+	// Guarded by console.mu.
+	line    string
+	drawn   bool
+	midLine bool
+}
+
+func startLiveStatus(progress *Progress, out *console) *liveStatus {
+	f, ok := out.w.(*os.File)
+	ls := &liveStatus{progress: progress, console: out, tty: ok && isTerminal(f), stop: make(chan struct{})}
+	if ls.tty {
+		out.mu.Lock()
+		out.status = ls
+		out.mu.Unlock()
+	}
+	ls.wg.Add(1)
+	go ls.refresh()
+	return ls
+}
+
+// Stop detaches the status line from the console and removes it.
+func (ls *liveStatus) Stop() {
+	close(ls.stop)
+	ls.wg.Wait()
+	ls.console.mu.Lock()
+	defer ls.console.mu.Unlock()
+	if ls.console.status == ls {
+		ls.clearLine()
+		ls.console.status = nil
+	}
+}
+
+func (ls *liveStatus) refresh() {
+	defer ls.wg.Done()
+	interval := statusRefreshPlain
+	if ls.tty {
+		interval = statusRefreshTTY
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ls.stop:
+			return
+		case <-ticker.C:
+			line := formatStatusLine(ls.progress.Snapshot(), time.Now())
+			if !ls.tty {
+				fmt.Fprintln(ls.console, line)
+				continue
+			}
+			ls.console.mu.Lock()
+			ls.line = truncateRunes(line, statusWidth())
+			ls.clearLine()
+			ls.drawLine()
+			ls.console.mu.Unlock()
+		}
+	}
+}
+
+// clearLine and drawLine must be called with console.mu held.
+func (ls *liveStatus) clearLine() {
+	if ls.drawn {
+		fmt.Fprint(ls.console.w, "\r\033[K")
+		ls.drawn = false
+	}
+}
+
+func (ls *liveStatus) drawLine() {
+	if ls.line != "" && !ls.midLine {
+		fmt.Fprint(ls.console.w, ls.line)
+		ls.drawn = true
+	}
+}
+
+// formatStatusLine summarises the current phase and the whole run.
+func formatStatusLine(s RunStatus, now time.Time) string {
+	var parts []string
+	for _, ph := range s.Phases {
+		if ph.ID != s.CurrentPhase {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("Phase %s %d/%d", ph.ID, ph.Done, ph.Total))
+		if ph.Skipped > 0 {
+			parts = append(parts, fmt.Sprintf("%d skipped", ph.Skipped))
+		}
+		if ph.Estimate > 0 {
+			parts = append(parts, fmt.Sprintf("%d/~%d %s", ph.Work, ph.Estimate, ph.workUnit()))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %s", ph.Work, ph.workUnit()))
+		}
+		if rate := ph.rate(now); rate > 0 {
+			parts = append(parts, fmt.Sprintf("%.1f/min", rate))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("total %d deleted, %d reactions", s.MessagesDeleted, s.ReactionsRemoved))
+	if s.Estimated {
+		if eta, ok := s.ETA(now); ok {
+			parts = append(parts, "ETA "+formatETA(eta))
+		} else {
+			parts = append(parts, "ETA …")
+		}
+	}
+	if wait := s.RateLimitedUntil.Sub(now); wait > 0 {
+		parts = append(parts, fmt.Sprintf("rate limited %ds", int(wait.Seconds()+0.5)))
+	}
+	return "📈 " + strings.Join(parts, " · ")
+}
+
+// formatETA renders a duration as e.g. "1h20m" or "45s".
+func formatETA(d time.Duration) string {
+	if d >= time.Minute {
+		d = d.Round(time.Minute)
+	} else {
+		d = d.Round(time.Second)
+	}
+	out := d.String()
+	if strings.HasSuffix(out, "m0s") {
+		out = strings.TrimSuffix(out, "0s")
+	}
+	return out
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// statusWidth returns the terminal width from $COLUMNS, or a default.
+func statusWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 10 {
+		return cols - 1
+	}
+	return defaultStatusWidth
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
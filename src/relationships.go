@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Relationship cleanup
+// =============================================================================
+
+// Each cleanup writes a new backup so a second run can't overwrite the list
+// saved by the first.
+const relationshipBackupPattern = "discord-purge-relationships-backup-%s.json"
+
+// RelationshipCleanup selects which relationships the cleanup removes.
+type RelationshipCleanup struct {
+	Friends  bool     // remove friends
+	Blocked  bool     // unblock blocked users
+	Outgoing bool     // cancel friend requests you sent
+	Incoming bool     // decline friend requests you received
+	Keep     []string // user IDs or usernames that are never touched
+}
+
+func (r RelationshipCleanup) any() bool {
+	return r.Friends || r.Blocked || r.Outgoing || r.Incoming
+}
+
+// removes reports whether the cleanup removes relationships of type relType.
+func (r RelationshipCleanup) removes(relType int) bool {
+	switch relType {
+	case RelationshipFriend:
+		return r.Friends
+	case RelationshipBlocked:
+		return r.Blocked
+	case RelationshipOutgoingReq:
+		return r.Outgoing
+	case RelationshipIncomingReq:
+		return r.Incoming
+	}
+	return false
+}
+
+// keeps reports whether a user is on the keep-list, by ID or username.
+func (r RelationshipCleanup) keeps(user User) bool {
+	for _, entry := range r.Keep {
+		if entry == user.ID || strings.EqualFold(entry, user.Username) {
+			return true
+		}
+	}
+	return false
+}
+
+// RelationshipCleanupResult records what a relationship cleanup changed.
+type RelationshipCleanupResult struct {
+	BackupPath        string `json:"backup_path"`
+	FriendsRemoved    int    `json:"friends_removed"`
+	Unblocked         int    `json:"unblocked"`
+	OutgoingCancelled int    `json:"outgoing_cancelled"`
+	IncomingDeclined  int    `json:"incoming_declined"`
+	Kept              int    `json:"kept"`
+}
+
+// relationshipActions labels the outcome of removing each relationship type,
+// for success and failure messages.
+var relationshipActions = map[int]struct{ done, failed string }{
+	RelationshipFriend:      {"Removed friend", "remove friend"},
+	RelationshipBlocked:     {"Unblocked", "unblock"},
+	RelationshipOutgoingReq: {"Cancelled request to", "cancel request to"},
+	RelationshipIncomingReq: {"Declined request from", "decline request from"},
+}
+
+// count records one removed relationship of type relType.
+func (r *RelationshipCleanupResult) count(relType int) {
+	switch relType {
+	case RelationshipFriend:
+		r.FriendsRemoved++
+	case RelationshipBlocked:
+		r.Unblocked++
+	case RelationshipOutgoingReq:
+		r.OutgoingCancelled++
+	case RelationshipIncomingReq:
+		r.IncomingDeclined++
+	}
+}
+
+// RelationshipBackup is the relationship list saved before a cleanup.
+type RelationshipBackup struct {
+	UserID        string         `json:"user_id"`
+	SavedAt       time.Time      `json:"saved_at"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// Save writes the relationship backup to path.
+func (b *RelationshipBackup) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding relationship backup: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing relationship backup: %w", err)
+	}
+	return nil
+}
+
+// CleanupRelationships backs up the relationship list and then removes the
+// selected kinds of relationships, skipping users on the keep-list. Nothing
+// is changed if the backup can't be written.
+func (c *DiscordClient) CleanupRelationships(cleanup RelationshipCleanup) (*RelationshipCleanupResult, error) {
+	rels, err := c.GetRelationships()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RelationshipCleanupResult{BackupPath: fmt.Sprintf(relationshipBackupPattern, time.Now().Format("20060102-150405"))}
+	backup := &RelationshipBackup{UserID: c.userID, SavedAt: time.Now(), Relationships: rels}
+	if err := backup.Save(result.BackupPath); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(c.out, "   💾 Relationship list saved to %s\n", result.BackupPath)
+
+	for _, rel := range rels {
+		if !cleanup.removes(rel.Type) {
+			continue
+		}
+		if cleanup.keeps(rel.User) {
+			result.Kept++
+			continue
+		}
+		action := relationshipActions[rel.Type]
+		err := c.RemoveRelationship(rel.User.ID)
+		if isSafetyHalt(err) {
+			return result, err
+		}
+		if err != nil {
+			fmt.Fprintf(c.out, "   ⚠️  Failed to %s %s: %v\n", action.failed, rel.User.Username, err)
+		} else {
+			result.count(rel.Type)
+			fmt.Fprintf(c.out, "   ✅ %s %s\n", action.done, rel.User.Username)
+		}
+		c.pacer.Wait(paceDiscovery)
+	}
+	if result.Kept > 0 {
+		fmt.Fprintf(c.out, "   ↪ Kept %d users on your keep-list\n", result.Kept)
+	}
+	return result, nil
+}
+
+// promptRelationshipCleanup asks which kinds of relationships to remove, for
+// each kind the account has, and which users to keep.
+func promptRelationshipCleanup(client *DiscordClient, cleanup *CleanupChoice) {
+	rels, err := client.GetRelationships()
+	if err != nil {
+		fmt.Printf("⚠️  Could not load relationships: %v\n\n", err)
+		return
+	}
+	counts := make(map[int]int)
+	for _, rel := range rels {
+		counts[rel.Type]++
+	}
+
+	for _, question := range []struct {
+		relType int
+		prompt  string
+		choice  *bool
+	}{
+		{RelationshipFriend, "Remove your %d friends? (yes/no): ", &cleanup.RemoveFriends},
+		{RelationshipBlocked, "Unblock the %d users you blocked? (yes/no): ", &cleanup.UnblockUsers},
+		{RelationshipOutgoingReq, "Cancel the %d friend requests you sent? (yes/no): ", &cleanup.CancelOutgoing},
+		{RelationshipIncomingReq, "Decline the %d friend requests you received? (yes/no): ", &cleanup.DeclineIncoming},
+	} {
+		if counts[question.relType] == 0 {
+			continue
+		}
+		fmt.Printf(question.prompt, counts[question.relType])
+		response := strings.TrimSpace(strings.ToLower(readInputLine()))
+		*question.choice = response == "yes" || response == "y"
+	}
+
+	if cleanup.relationshipCleanup().any() {
+		fmt.Print("Users to keep (IDs or usernames, comma-separated; Enter for none): ")
+		cleanup.KeepUsers = splitIDList(readInputLine())
+	}
+	fmt.Println()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// =============================================================================
+// End-of-run report
+// =============================================================================
+
+// RunReport is the JSON report written at the end of a run.
+type RunReport struct {
+	GeneratedAt    time.Time      `json:"generated_at"`
+	UserID         string         `json:"user_id"`
+	Username       string         `json:"username"`
+	Bot            bool           `json:"bot,omitempty"`
+	Profile        string         `json:"profile,omitempty"`
+	ElapsedSeconds float64        `json:"elapsed_seconds"`
+	Purge          PurgeStats     `json:"purge"`
+	Cleanup        *CleanupReport `json:"cleanup,omitempty"`
+}
+
+// CleanupReport records the results of the optional post-purge cleanup.
+// In a dry run only the notes and apps are filled in, with what would be
+// cleared.
+type CleanupReport struct {
+	DryRun         bool                       `json:"dry_run,omitempty"`
+	FriendsRemoved int                        `json:"friends_removed"`
+	ServersLeft    int                        `json:"servers_left"`
+	Relationships  *RelationshipCleanupResult `json:"relationships,omitempty"`
+	ServerLeave    *ServerLeaveResult         `json:"server_leave,omitempty"`
+	ProfileScrub   *ProfileScrubResult        `json:"profile_scrub,omitempty"`
+	NotesCleared   []string                   `json:"notes_cleared,omitempty"`
+	AppsRevoked    []AuthorizedApp            `json:"apps_revoked,omitempty"`
+}
+
+// NewRunReport builds a report for the client's account from purge stats.
+func (c *DiscordClient) NewRunReport(profile string, stats PurgeStats) *RunReport {
+	return &RunReport{
+		GeneratedAt:    time.Now(),
+		UserID:         c.userID,
+		Username:       c.username,
+		Bot:            c.bot,
+		Profile:        profile,
+		ElapsedSeconds: stats.TimeElapsed.Seconds(),
+		Purge:          stats,
+	}
+}
+
+// Write saves the report as indented JSON.
+func (r *RunReport) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// =============================================================================
+// Account safety detection
+// =============================================================================
+
+// Kinds of responses that mean continuing would put the account at risk.
+const (
+	haltCloudflareBan   = "cloudflare_ban"
+	haltCaptcha         = "captcha"
+	haltVerification    = "verification_required"
+	haltStopped         = "stopped"
+	verificationErrCode = 40002 // "You need to verify your account in order to perform this action"
+)
+
+// SafetyHaltError is returned by requestWithBody when Discord answers with a
+// Cloudflare ban, a captcha challenge, or an account-verification block, or
+// when a stop was requested. Once one has been seen the client refuses to
+// send any further requests.
+type SafetyHaltError struct {
+	Kind   string
+	Method string
+	Path   string
+	Status int
+	Detail string
+}
+
+func (e *SafetyHaltError) Error() string {
+	switch e.Kind {
+	case haltCloudflareBan:
+		return fmt.Sprintf("blocked by Cloudflare on %s %s (HTTP %d, %s)", e.Method, e.Path, e.Status, e.Detail)
+	case haltCaptcha:
+		return fmt.Sprintf("captcha challenge on %s %s (HTTP %d, %s)", e.Method, e.Path, e.Status, e.Detail)
+	case haltVerification:
+		return fmt.Sprintf("account verification required on %s %s (HTTP %d, %s)", e.Method, e.Path, e.Status, e.Detail)
+	case haltStopped:
+		return fmt.Sprintf("stopped before %s %s (%s)", e.Method, e.Path, e.Detail)
+	}
+	return fmt.Sprintf("unsafe response on %s %s (HTTP %d)", e.Method, e.Path, e.Status)
+}
+
+// Explanation returns user-facing guidance for the halt.
+func (e *SafetyHaltError) Explanation() []string {
+	switch e.Kind {
+	case haltCloudflareBan:
+		return []string{
+			"Discord's Cloudflare edge answered with a non-JSON 429 (error 1015).",
+			"Your IP address has been temporarily banned for sending too many requests.",
+			"Wait at least an hour (ideally longer) before running the tool again.",
+		}
+	case haltCaptcha:
+		return []string{
+			"Discord asked for a captcha before allowing this action.",
+			"Automated tools cannot solve captchas, and retrying can flag the account.",
+			"Open Discord in your browser, use it normally for a while, then resume later.",
+		}
+	case haltVerification:
+		return []string{
+			"Discord requires the account to be verified (email or phone) before continuing.",
+			"Complete the verification in the official Discord client, then resume later.",
+		}
+	case haltStopped:
+		return []string{
+			"The run was stopped on request before finishing.",
+			"Completed servers and DM channels are saved in the checkpoint.",
+		}
+	}
+	return []string{"Discord returned a response that makes continuing unsafe."}
+}
+
+// classifySafetyResponse inspects a response and returns a SafetyHaltError if
+// it is a Cloudflare ban, captcha challenge, or verification block.
+func classifySafetyResponse(method, path string, status int, body []byte) *SafetyHaltError {
+	trimmed := bytes.TrimSpace(body)
+
+	// Cloudflare bans come back as HTML/plain text instead of Discord's JSON.
+	if status == 429 && !json.Valid(trimmed) {
+		detail := "non-JSON rate limit"
+		if bytes.Contains(trimmed, []byte("1015")) {
+			detail = "error 1015"
+		}
+		return &SafetyHaltError{Kind: haltCloudflareBan, Method: method, Path: path, Status: status, Detail: detail}
+	}
+	if status >= 400 && bytes.Contains(trimmed, []byte("error code: 1015")) {
+		return &SafetyHaltError{Kind: haltCloudflareBan, Method: method, Path: path, Status: status, Detail: "error 1015"}
+	}
+
+	if status < 400 || !json.Valid(trimmed) {
+		return nil
+	}
+
+	var payload struct {
+		CaptchaKey     []string `json:"captcha_key"`
+		CaptchaService string   `json:"captcha_service"`
+		Code           int      `json:"code"`
+		Message        string   `json:"message"`
+	}
+	if json.Unmarshal(trimmed, &payload) != nil {
+		return nil
+	}
+
+	if len(payload.CaptchaKey) > 0 {
+		detail := strings.Join(payload.CaptchaKey, ", ")
+		if payload.CaptchaService != "" {
+			detail += " via " + payload.CaptchaService
+		}
+		return &SafetyHaltError{Kind: haltCaptcha, Method: method, Path: path, Status: status, Detail: detail}
+	}
+
+	if payload.Code == verificationErrCode {
+		return &SafetyHaltError{
+			Kind:   haltVerification,
+			Method: method,
+			Path:   path,
+			Status: status,
+			Detail: fmt.Sprintf("code %d: %s", payload.Code, payload.Message),
+		}
+	}
+
+	return nil
+}
+
+// isSafetyHalt reports whether err is (or wraps) a SafetyHaltError.
+func isSafetyHalt(err error) bool {
+	var haltErr *SafetyHaltError
+	return errors.As(err, &haltErr)
+}
+
+// halted reports whether the client has stopped because of an unsafe response
+// or a stop request.
+func (c *DiscordClient) halted() bool {
+	return c.halt != nil
+}
+
+// RequestStop asks the client to stop before its next request. It is safe to
+// call from another goroutine (e.g. a signal handler); the run then winds down
+// exactly like a safety halt, saving its checkpoint.
+func (c *DiscordClient) RequestStop() {
+	c.stopRequested.Store(true)
+}
+
+// printSafetyHalt prints a clear explanation of why the run was stopped.
+func printSafetyHalt(w io.Writer, haltErr *SafetyHaltError) {
+	fmt.Fprintln(w)
+	if haltErr.Kind == haltStopped {
+		fmt.Fprintln(w, "⏹️  RUN STOPPED")
+	} else {
+		fmt.Fprintln(w, "🛑 RUN HALTED TO PROTECT YOUR ACCOUNT")
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+	fmt.Fprintf(w, "   %v\n", haltErr)
+	for _, line := range haltErr.Explanation() {
+		fmt.Fprintf(w, "   %s\n", line)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+}
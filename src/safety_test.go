@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestClassifySafetyResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string // halt kind, or "" for none
+	}{
+		{"success", 200, `{"id":"1"}`, ""},
+		{"json rate limit", 429, `{"message":"You are being rate limited.","retry_after":1.5,"global":false}`, ""},
+		{"cloudflare html rate limit", 429, `<html><body>Access denied</body></html>`, haltCloudflareBan},
+		{"cloudflare 1015 text", 403, `error code: 1015`, haltCloudflareBan},
+		{"captcha", 400, `{"captcha_key":["captcha-required"],"captcha_service":"hcaptcha"}`, haltCaptcha},
+		{"verification", 403, `{"message":"You need to verify your account in order to perform this action.","code":40002}`, haltVerification},
+		{"missing access", 403, `{"message":"Missing Access","code":50001}`, ""},
+		{"server error page", 502, `<html>Bad Gateway</html>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			halt := classifySafetyResponse("GET", "/users/@me", tt.status, []byte(tt.body))
+			switch {
+			case tt.want == "" && halt != nil:
+				t.Errorf("halted with %s (%v), want no halt", halt.Kind, halt)
+			case tt.want != "" && halt == nil:
+				t.Errorf("no halt, want %s", tt.want)
+			case halt != nil && halt.Kind != tt.want:
+				t.Errorf("halt kind = %s, want %s", halt.Kind, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Account profile scrub
+// =============================================================================
+
+const (
+	cdnBase = "https://cdn.discordapp.com"
+
+	// Each scrub writes a new backup so a second scrub can't overwrite the
+	// values saved by the first with empty ones.
+	profileBackupPattern = "discord-purge-profile-backup-%s.json"
+)
+
+// Connection is a linked third-party account.
+type Connection struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Verified   bool   `json:"verified"`
+	Visibility int    `json:"visibility"`
+}
+
+// CustomStatus is the status text and emoji shown under the username.
+type CustomStatus struct {
+	Text      string  `json:"text,omitempty"`
+	EmojiID   *string `json:"emoji_id,omitempty"`
+	EmojiName string  `json:"emoji_name,omitempty"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+}
+
+// AccountProfile is the public profile that stays behind after a purge.
+// Avatar and Banner are image hashes; the images themselves are saved as
+// data URIs so they can be uploaded again. PronounsUnknown is set when the
+// pronouns couldn't be read, so neither a scrub nor a restore touches them.
+type AccountProfile struct {
+	UserID          string        `json:"user_id"`
+	SavedAt         time.Time     `json:"saved_at"`
+	GlobalName      string        `json:"global_name"`
+	Bio             string        `json:"bio"`
+	Pronouns        string        `json:"pronouns"`
+	PronounsUnknown bool          `json:"pronouns_unknown,omitempty"`
+	Avatar          string        `json:"avatar"`
+	AvatarImage     string        `json:"avatar_image,omitempty"`
+	Banner          string        `json:"banner"`
+	BannerImage     string        `json:"banner_image,omitempty"`
+	CustomStatus    *CustomStatus `json:"custom_status,omitempty"`
+	Connections     []Connection  `json:"connections"`
+}
+
+// ProfileScrubResult records what a scrub reset.
+type ProfileScrubResult struct {
+	BackupPath         string   `json:"backup_path"`
+	FieldsReset        []string `json:"fields_reset"`
+	ConnectionsRemoved int      `json:"connections_removed"`
+}
+
+// GetAccountProfile reads the account's current profile, status and
+// connections, downloading the avatar and banner images.
+func (c *DiscordClient) GetAccountProfile() (*AccountProfile, error) {
+	body, status, err := c.request("GET", "/users/@me")
+	if err != nil {
+		return nil, fmt.Errorf("fetching account: %w", err)
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("fetching account: HTTP %d", status)
+	}
+	var me struct {
+		GlobalName *string `json:"global_name"`
+		Avatar     *string `json:"avatar"`
+		Banner     *string `json:"banner"`
+		Bio        string  `json:"bio"`
+	}
+	if err := json.Unmarshal(body, &me); err != nil {
+		return nil, fmt.Errorf("parsing account: %w", err)
+	}
+
+	profile := &AccountProfile{UserID: c.userID, SavedAt: time.Now(), Bio: me.Bio}
+	if me.GlobalName != nil {
+		profile.GlobalName = *me.GlobalName
+	}
+	if me.Avatar != nil {
+		profile.Avatar = *me.Avatar
+	}
+	if me.Banner != nil {
+		profile.Banner = *me.Banner
+	}
+
+	// The profile endpoint carries the pronouns (and the bio on newer accounts).
+	body, status, err = c.request("GET", fmt.Sprintf("/users/%s/profile", c.userID))
+	if err != nil {
+		return nil, fmt.Errorf("fetching profile: %w", err)
+	}
+	var userProfile struct {
+		UserProfile struct {
+			Bio      string `json:"bio"`
+			Pronouns string `json:"pronouns"`
+		} `json:"user_profile"`
+	}
+	if status != 200 {
+		err = fmt.Errorf("HTTP %d", status)
+	} else {
+		err = json.Unmarshal(body, &userProfile)
+	}
+	if err == nil {
+		if userProfile.UserProfile.Bio != "" {
+			profile.Bio = userProfile.UserProfile.Bio
+		}
+		profile.Pronouns = userProfile.UserProfile.Pronouns
+	} else {
+		profile.PronounsUnknown = true
+		fmt.Fprintf(c.out, "   ⚠️  Could not read your pronouns (%v); they will be kept.\n", err)
+	}
+	c.pacer.Wait(paceDiscovery)
+
+	body, status, err = c.request("GET", "/users/@me/settings")
+	if err != nil {
+		return nil, fmt.Errorf("fetching settings: %w", err)
+	}
+	if status == 200 {
+		var settings struct {
+			CustomStatus *CustomStatus `json:"custom_status"`
+		}
+		if json.Unmarshal(body, &settings) == nil {
+			profile.CustomStatus = settings.CustomStatus
+		}
+	}
+	c.pacer.Wait(paceDiscovery)
+
+	body, status, err = c.request("GET", "/users/@me/connections")
+	if err != nil {
+		return nil, fmt.Errorf("fetching connections: %w", err)
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("fetching connections: HTTP %d", status)
+	}
+	if err := json.Unmarshal(body, &profile.Connections); err != nil {
+		return nil, fmt.Errorf("parsing connections: %w", err)
+	}
+
+	if profile.Avatar != "" {
+		profile.AvatarImage, err = c.downloadImage(fmt.Sprintf("%s/avatars/%s/%s.%s?size=1024", cdnBase, c.userID, profile.Avatar, imageExtension(profile.Avatar)))
+		if err != nil {
+			fmt.Fprintf(c.out, "   ⚠️  Could not save your avatar image (%v); the avatar will be kept.\n", err)
+		}
+	}
+	if profile.Banner != "" {
+		profile.BannerImage, err = c.downloadImage(fmt.Sprintf("%s/banners/%s/%s.%s?size=1024", cdnBase, c.userID, profile.Banner, imageExtension(profile.Banner)))
+		if err != nil {
+			fmt.Fprintf(c.out, "   ⚠️  Could not save your banner image (%v); the banner will be kept.\n", err)
+		}
+	}
+	return profile, nil
+}
+
+// imageExtension returns the CDN extension for an image hash; animated
+// images have hashes starting with "a_".
+func imageExtension(hash string) string {
+	if strings.HasPrefix(hash, "a_") {
+		return "gif"
+	}
+	return "png"
+}
+
+// downloadImage fetches a CDN image and returns it as a data URI, the form
+// the API accepts when uploading it again.
+func (c *DiscordClient) downloadImage(url string) (string, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// LoadAccountProfile reads a profile backup.
+func LoadAccountProfile(path string) (*AccountProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading profile backup: %w", err)
+	}
+	var profile AccountProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("parsing profile backup: %w", err)
+	}
+	return &profile, nil
+}
+
+// Save writes the profile backup to path.
+func (p *AccountProfile) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding profile backup: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing profile backup: %w", err)
+	}
+	return nil
+}
+
+// ScrubProfile backs up the account's profile and then resets the display
+// name, bio, pronouns, avatar, banner and custom status and removes linked
+// connections. Nothing is changed if the backup can't be written; an avatar
+// or banner whose image couldn't be saved is left in place.
+func (c *DiscordClient) ScrubProfile() (*ProfileScrubResult, error) {
+	profile, err := c.GetAccountProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProfileScrubResult{BackupPath: fmt.Sprintf(profileBackupPattern, time.Now().Format("20060102-150405"))}
+	if err := profile.Save(result.BackupPath); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(c.out, "   💾 Previous profile saved to %s\n", result.BackupPath)
+
+	account := make(map[string]any)
+	var accountFields []string
+	if profile.GlobalName != "" {
+		accountFields = append(accountFields, "global_name")
+	}
+	if profile.Avatar != "" && profile.AvatarImage != "" {
+		accountFields = append(accountFields, "avatar")
+	}
+	if profile.Banner != "" && profile.BannerImage != "" {
+		accountFields = append(accountFields, "banner")
+	}
+	for _, field := range accountFields {
+		account[field] = nil
+	}
+	if len(account) > 0 && c.patchJSON("/users/@me", account, "display name, avatar and banner") {
+		result.FieldsReset = append(result.FieldsReset, accountFields...)
+	}
+
+	// Only fields whose value made it into the backup are reset.
+	userProfile := make(map[string]any)
+	var profileFields []string
+	if profile.Bio != "" {
+		profileFields = append(profileFields, "bio")
+	}
+	if profile.Pronouns != "" && !profile.PronounsUnknown {
+		profileFields = append(profileFields, "pronouns")
+	}
+	for _, field := range profileFields {
+		userProfile[field] = ""
+	}
+	if len(userProfile) > 0 && c.patchJSON("/users/@me/profile", userProfile, strings.Join(profileFields, " and ")) {
+		result.FieldsReset = append(result.FieldsReset, profileFields...)
+	}
+
+	if profile.CustomStatus != nil {
+		if c.patchJSON("/users/@me/settings", map[string]any{"custom_status": nil}, "custom status") {
+			result.FieldsReset = append(result.FieldsReset, "custom_status")
+		}
+	}
+
+	for _, conn := range profile.Connections {
+		_, status, err := c.request("DELETE", fmt.Sprintf("/users/@me/connections/%s/%s", conn.Type, conn.ID))
+		if isSafetyHalt(err) {
+			return result, err
+		}
+		if err == nil && (status == 204 || status == 200 || status == 404) {
+			result.ConnectionsRemoved++
+			fmt.Fprintf(c.out, "   ✅ Removed %s connection: %s\n", conn.Type, conn.Name)
+		} else {
+			fmt.Fprintf(c.out, "   ⚠️  Failed to remove %s connection %s: %v\n", conn.Type, conn.Name, statusError(status, err))
+		}
+		c.pacer.Wait(paceDiscovery)
+	}
+
+	return result, nil
+}
+
+// RestoreProfile applies a profile backup and returns an error naming the
+// parts that couldn't be restored. Connections can only be linked again from
+// the Discord client, so they are listed instead.
+func (c *DiscordClient) RestoreProfile(profile *AccountProfile) error {
+	if profile.UserID != c.userID {
+		return fmt.Errorf("the backup belongs to user %s, not %s", profile.UserID, c.userID)
+	}
+
+	account := map[string]any{"global_name": nullable(profile.GlobalName)}
+	if profile.AvatarImage != "" {
+		account["avatar"] = profile.AvatarImage
+	}
+	if profile.BannerImage != "" {
+		account["banner"] = profile.BannerImage
+	}
+	var failed []string
+	if !c.patchJSON("/users/@me", account, "display name, avatar and banner") {
+		failed = append(failed, "display name, avatar and banner")
+	}
+	userProfile, label := map[string]any{"bio": profile.Bio}, "bio"
+	if !profile.PronounsUnknown {
+		userProfile["pronouns"], label = profile.Pronouns, "bio and pronouns"
+	}
+	if !c.patchJSON("/users/@me/profile", userProfile, label) {
+		failed = append(failed, label)
+	}
+	if profile.CustomStatus != nil && !c.patchJSON("/users/@me/settings", map[string]any{"custom_status": profile.CustomStatus}, "custom status") {
+		failed = append(failed, "custom status")
+	}
+	if c.halted() {
+		return c.halt
+	}
+
+	if len(profile.Connections) > 0 {
+		fmt.Fprintln(c.out, "   🔗 Connections have to be linked again in Discord (User Settings → Connections):")
+		for _, conn := range profile.Connections {
+			fmt.Fprintf(c.out, "      • %s: %s\n", conn.Type, conn.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not restore the %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// patchJSON sends a PATCH with a JSON body and reports the outcome.
+func (c *DiscordClient) patchJSON(path string, fields map[string]any, label string) bool {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		fmt.Fprintf(c.out, "   ❌ Failed to update %s: %v\n", label, err)
+		return false
+	}
+	body, status, err := c.requestWithBody("PATCH", path, string(payload))
+	c.pacer.Wait(paceDiscovery)
+	if err == nil && status == 200 {
+		fmt.Fprintf(c.out, "   ✅ Updated %s\n", label)
+		return true
+	}
+	if err == nil {
+		if detail := formatAPIError(body); detail != "" {
+			err = fmt.Errorf("HTTP %d, %s", status, detail)
+		}
+	}
+	fmt.Fprintf(c.out, "   ❌ Failed to update %s: %v\n", label, statusError(status, err))
+	return false
+}
+
+// statusError describes a failed request by its error or HTTP status.
+func statusError(status int, err error) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
+
+// nullable maps an empty string to JSON null.
+func nullable(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// =============================================================================
+// Thread leaving
+// =============================================================================
+
+// ThreadLeaveResult records the threads left after the purge.
+type ThreadLeaveResult struct {
+	Left         int `json:"left"`
+	ArchivedKept int `json:"archived_kept"`
+}
+
+// LeaveThread removes the current user from a thread. Discord refuses this
+// for archived threads.
+func (c *DiscordClient) LeaveThread(threadID string) error {
+	body, status, err := c.request("DELETE", fmt.Sprintf("/channels/%s/thread-members/@me", threadID))
+	if err != nil {
+		return err
+	}
+	if status == 200 || status == 204 || status == 404 {
+		return nil
+	}
+	if detail := formatAPIError(body); detail != "" {
+		return fmt.Errorf("HTTP %d, %s", status, detail)
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
+
+// leaveThreads leaves every joined thread in scope, in each server. Threads
+// are taken from the lists gathered for Phase 3, and servers Phase 3 didn't
+// scan are discovered first. Archived threads can't be left and are only
+// counted. A dry run only lists them. It returns nil unless LeaveThreads is
+// set.
+func (c *DiscordClient) leaveThreads(guilds []Guild, serverStats *[]ServerStat, options PurgeOptions) *ThreadLeaveResult {
+	if !options.LeaveThreads {
+		return nil
+	}
+	fmt.Fprintln(c.out, "🧵 Leaving threads you joined...")
+
+	result := &ThreadLeaveResult{}
+	for _, guild := range guilds {
+		if c.halted() {
+			break
+		}
+		name := displayGuildName(guild)
+		if c.joinedThreads[guild.ID] == nil {
+			c.discoverAllGuildChannelsAndThreads(guild.ID)
+		}
+		joined := c.joinedThreads[guild.ID]
+		ids := make([]string, 0, len(joined))
+		for id := range joined {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		left := 0
+		for _, id := range c.filterGuildChannels(options, guild.ID, ids) {
+			if c.halted() {
+				break
+			}
+			thread := joined[id]
+			label := thread.ID
+			if thread.Name != "" {
+				label = "#" + thread.Name
+			}
+			if thread.ThreadMetadata != nil && thread.ThreadMetadata.Archived {
+				result.ArchivedKept++
+				continue
+			}
+			if options.DryRun {
+				fmt.Fprintf(c.out, "   🧪 Would leave thread %s in %s\n", label, name)
+				left++
+				continue
+			}
+			err := c.LeaveThread(id)
+			c.pacer.Wait(paceDiscovery)
+			if isSafetyHalt(err) {
+				break
+			}
+			if err != nil {
+				fmt.Fprintf(c.out, "   ⚠️  Failed to leave thread %s in %s: %v\n", label, name, err)
+				continue
+			}
+			fmt.Fprintf(c.out, "   ✅ Left thread %s in %s\n", label, name)
+			left++
+		}
+		if left > 0 {
+			serverStat(serverStats, guild.ID, name).ThreadsLeft = left
+			result.Left += left
+		}
+	}
+
+	if result.ArchivedKept > 0 {
+		fmt.Fprintf(c.out, "   ↪ %d joined threads are archived; Discord only lets you leave them once unarchived\n", result.ArchivedKept)
+	}
+	if result.Left == 0 && result.ArchivedKept == 0 {
+		fmt.Fprintln(c.out, "   ✓ No joined threads found")
+	}
+	fmt.Fprintln(c.out)
+	return result
+}
@@ -0,0 +1,546 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Local web UI
+// =============================================================================
+
+const webSessionCookie = "discord_purge_session"
+
+// webUI serves the scope picker and live progress pages on 127.0.0.1. The
+// access token printed in the terminal is accepted once and exchanged for a
+// session cookie, so the link is useless after it has been opened.
+type webUI struct {
+	client          *DiscordClient
+	dataPackagePath string
+	profileName     string
+	base            PurgeOptions
+	guilds          []Guild
+	dms             []Channel
+	relationships   []Relationship
+	checkpoint      *Checkpoint
+
+	host        string
+	accessToken string
+	sessionID   string
+	csrfToken   string
+
+	mu        sync.Mutex
+	tokenUsed bool
+	started   bool
+	report    *RunReport
+}
+
+// webItem is one row of a selection list.
+type webItem struct {
+	ID      string
+	Name    string
+	Detail  string
+	Checked bool
+	// Locked marks servers and DMs the profile excludes; the form cannot
+	// bring them back into scope.
+	Locked bool
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("reading random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// runWebUI serves the web UI until the process is interrupted. base holds the
+// profile's settings (or none); the scope picked in the browser replaces its
+// server and DM lists. It returns the process exit code.
+func runWebUI(client *DiscordClient, dataPackagePath, profileName string, base PurgeOptions, guilds []Guild, dms []Channel) int {
+	rels, err := client.GetRelationships()
+	if err != nil {
+		fmt.Fprintf(client.out, "⚠️  Could not load relationships: %v\n", err)
+	}
+	checkpoint, err := LoadCheckpoint(checkpointFile)
+	if err != nil || (checkpoint != nil && checkpoint.UserID != client.userID) {
+		checkpoint = nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintf(client.out, "❌ Starting web UI: %v\n", err)
+		return 1
+	}
+
+	ui := &webUI{
+		client:          client,
+		dataPackagePath: dataPackagePath,
+		profileName:     profileName,
+		base:            base,
+		guilds:          guilds,
+		dms:             dms,
+		relationships:   rels,
+		checkpoint:      checkpoint,
+		host:            listener.Addr().String(),
+		accessToken:     randomToken(),
+		sessionID:       randomToken(),
+		csrfToken:       randomToken(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", ui.handleIndex)
+	mux.HandleFunc("/start", ui.handleStart)
+	mux.HandleFunc("/progress", ui.handleProgress)
+	mux.HandleFunc("/status", ui.handleStatus)
+	mux.HandleFunc("/report", ui.handleReport)
+
+	fmt.Fprintln(client.out, "🌐 Web UI running. Open this link in your browser (it works once):")
+	fmt.Fprintf(client.out, "   http://%s/?token=%s\n", ui.host, ui.accessToken)
+	fmt.Fprintln(client.out, "   Press Ctrl+C here to quit.")
+	fmt.Fprintln(client.out)
+
+	if err := http.Serve(listener, mux); err != nil {
+		fmt.Fprintf(client.out, "❌ Web UI stopped: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// authorize checks the session cookie, or exchanges the one-time access token
+// for one. It writes the response itself and returns false when the request
+// must not be handled.
+func (u *webUI) authorize(w http.ResponseWriter, r *http.Request) bool {
+	// Only answer requests addressed to our own host:port, which defeats DNS
+	// rebinding from other sites.
+	if r.Host != u.host {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+
+	if cookie, err := r.Cookie(webSessionCookie); err == nil &&
+		subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(u.sessionID)) == 1 {
+		return true
+	}
+
+	if token := r.URL.Query().Get("token"); token != "" {
+		u.mu.Lock()
+		valid := !u.tokenUsed && subtle.ConstantTimeCompare([]byte(token), []byte(u.accessToken)) == 1
+		if valid {
+			u.tokenUsed = true
+		}
+		u.mu.Unlock()
+
+		if valid {
+			http.SetCookie(w, &http.Cookie{
+				Name:     webSessionCookie,
+				Value:    u.sessionID,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+			return false
+		}
+	}
+
+	http.Error(w, "forbidden: open the link printed in the terminal (each link works once)", http.StatusForbidden)
+	return false
+}
+
+func (u *webUI) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if !u.authorize(w, r) {
+		return
+	}
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	u.mu.Lock()
+	started := u.started
+	u.mu.Unlock()
+	if started {
+		http.Redirect(w, r, "/progress", http.StatusSeeOther)
+		return
+	}
+
+	includeMode := u.base.includeMode()
+	picked := func(included, excluded map[string]bool, id string) bool {
+		if includeMode {
+			return included[id]
+		}
+		return excluded[id]
+	}
+
+	data := struct {
+		Username      string
+		Profile       string
+		IncludeMode   bool
+		Guilds        []webItem
+		DMs           []webItem
+		Relationships []webItem
+		Phases        []webItem
+		ProfileRules  []string
+		Checkpoint    *Checkpoint
+		CSRF          string
+	}{
+		Username:     u.client.username,
+		Profile:      u.profileName,
+		IncludeMode:  includeMode,
+		ProfileRules: u.profileRules(),
+		Checkpoint:   u.checkpoint,
+		CSRF:         u.csrfToken,
+	}
+	for _, guild := range u.guilds {
+		data.Guilds = append(data.Guilds, webItem{
+			ID:      guild.ID,
+			Name:    displayGuildName(guild),
+			Checked: picked(u.base.IncludedGuildIDs, u.base.ExcludedGuildIDs, guild.ID),
+			Locked:  u.base.ExcludedGuildIDs[guild.ID],
+		})
+	}
+	for _, ch := range u.dms {
+		data.DMs = append(data.DMs, webItem{
+			ID:      ch.ID,
+			Name:    describeChannel(ch),
+			Checked: picked(u.base.IncludedDMChannelIDs, u.base.ExcludedDMChannelIDs, ch.ID),
+			Locked:  u.base.ExcludedDMChannelIDs[ch.ID],
+		})
+	}
+	for _, rel := range u.relationships {
+		data.Relationships = append(data.Relationships, webItem{
+			ID:      rel.User.ID,
+			Name:    rel.User.Username,
+			Detail:  relationshipLabel(rel.Type),
+			Checked: u.base.recipientExcluded(rel.User),
+		})
+	}
+	for _, phase := range phaseLabels {
+		data.Phases = append(data.Phases, webItem{ID: phase.ID, Name: phase.Label, Checked: u.base.runsPhase(phase.ID)})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webIndexTemplate.Execute(w, data); err != nil {
+		fmt.Fprintf(u.client.out, "⚠️  Rendering web UI: %v\n", err)
+	}
+}
+
+func (u *webUI) handleStart(w http.ResponseWriter, r *http.Request) {
+	if !u.authorize(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.PostForm.Get("csrf")), []byte(u.csrfToken)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if r.PostForm.Get("confirm") != "yes" {
+		http.Error(w, "Tick the confirmation box to start the purge.", http.StatusBadRequest)
+		return
+	}
+
+	options := u.formOptions(r)
+	if r.PostForm.Get("mode") == "include" && !options.includeMode() {
+		// An empty include list would otherwise fall back to purging everything.
+		http.Error(w, "Include-only mode needs at least one ticked server or DM.", http.StatusBadRequest)
+		return
+	}
+
+	u.mu.Lock()
+	if u.started {
+		u.mu.Unlock()
+		http.Redirect(w, r, "/progress", http.StatusSeeOther)
+		return
+	}
+	u.started = true
+	u.mu.Unlock()
+
+	fmt.Fprintln(u.client.out, "🌐 Purge started from the web UI.")
+	fmt.Fprintln(u.client.out)
+	go func() {
+		stats := u.client.PurgeAll(u.dataPackagePath, options)
+		report := u.client.NewRunReport(u.profileName, stats)
+		writeReport(report, options.ReportPath)
+
+		u.mu.Lock()
+		u.report = report
+		u.mu.Unlock()
+		fmt.Fprintln(u.client.out)
+		fmt.Fprintln(u.client.out, "🌐 Run finished. Download the report from the web UI, then press Ctrl+C to quit.")
+	}()
+
+	http.Redirect(w, r, "/progress", http.StatusSeeOther)
+}
+
+// formOptions applies the browser's selection on top of the base options.
+// The profile's exclusions always stay in force: ticked servers and DMs are
+// added to them in except mode, and its channel, category, name-pattern and
+// relationship rules (listed on the page) carry over unchanged. Individually
+// included channels only make sense in only mode, so except mode drops them.
+func (u *webUI) formOptions(r *http.Request) PurgeOptions {
+	options := u.base
+	options.ExcludedGuildIDs = make(map[string]bool)
+	for id := range u.base.ExcludedGuildIDs {
+		options.ExcludedGuildIDs[id] = true
+	}
+	options.ExcludedDMChannelIDs = make(map[string]bool)
+	for id := range u.base.ExcludedDMChannelIDs {
+		options.ExcludedDMChannelIDs[id] = true
+	}
+	options.IncludedGuildIDs = nil
+	options.IncludedDMChannelIDs = nil
+
+	guilds := idSet(r.PostForm["guild"])
+	dms := idSet(r.PostForm["dm"])
+	if r.PostForm.Get("mode") == "include" {
+		options.IncludedGuildIDs = guilds
+		options.IncludedDMChannelIDs = dms
+	} else {
+		options.IncludedChannelIDs = nil
+		for id := range guilds {
+			options.ExcludedGuildIDs[id] = true
+		}
+		for id := range dms {
+			options.ExcludedDMChannelIDs[id] = true
+		}
+	}
+
+	recipients := make(map[string]bool)
+	for id := range u.base.ExcludedDMRecipients {
+		recipients[id] = true
+	}
+	for _, id := range r.PostForm["recipient"] {
+		recipients[id] = true
+	}
+	options.ExcludedDMRecipients = recipients
+
+	options.Phases = idSet(r.PostForm["phase"])
+	options.Resume = nil
+	if r.PostForm.Get("resume") == "yes" {
+		options.Resume = u.checkpoint
+	}
+	return options
+}
+
+// profileRules describes the profile's scope rules that the form has no
+// controls for, so the page can show what else applies to the run.
+func (u *webUI) profileRules() []string {
+	var rules []string
+	if ids := sortedKeys(u.base.ExcludedChannelIDs); len(ids) > 0 {
+		rules = append(rules, "Excluded channels (and their threads): "+strings.Join(ids, ", "))
+	}
+	if ids := sortedKeys(u.base.ExcludedCategoryIDs); len(ids) > 0 {
+		rules = append(rules, "Excluded categories: "+strings.Join(ids, ", "))
+	}
+	if len(u.base.ExcludedGuildNamePatterns) > 0 {
+		var patterns []string
+		for _, pattern := range u.base.ExcludedGuildNamePatterns {
+			patterns = append(patterns, pattern.String())
+		}
+		rules = append(rules, "Servers excluded by name: "+strings.Join(patterns, ", "))
+	}
+	if len(u.base.ExcludedRelationshipTypes) > 0 {
+		var types []string
+		for relType := range u.base.ExcludedRelationshipTypes {
+			types = append(types, relationshipLabel(relType))
+		}
+		sort.Strings(types)
+		rules = append(rules, "DMs kept for relationship types: "+strings.Join(types, ", "))
+	}
+	if ids := sortedKeys(u.base.IncludedChannelIDs); len(ids) > 0 {
+		rules = append(rules, "Included channels (only mode; ignored in except mode): "+strings.Join(ids, ", "))
+	}
+	return rules
+}
+
+// sortedKeys returns the keys of an ID set in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (u *webUI) handleProgress(w http.ResponseWriter, r *http.Request) {
+	if !u.authorize(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webProgressTemplate.Execute(w, nil); err != nil {
+		fmt.Fprintf(u.client.out, "⚠️  Rendering web UI: %v\n", err)
+	}
+}
+
+func (u *webUI) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !u.authorize(w, r) {
+		return
+	}
+	snapshot := u.client.progress.Snapshot()
+	etaSeconds := -1
+	if eta, ok := snapshot.ETA(time.Now()); ok && snapshot.Estimated && snapshot.Running {
+		etaSeconds = int(eta.Seconds())
+	}
+
+	u.mu.Lock()
+	status := struct {
+		RunStatus
+		ETASeconds  int  `json:"eta_seconds"`
+		Started     bool `json:"started"`
+		ReportReady bool `json:"report_ready"`
+	}{snapshot, etaSeconds, u.started, u.report != nil}
+	u.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func (u *webUI) handleReport(w http.ResponseWriter, r *http.Request) {
+	if !u.authorize(w, r) {
+		return
+	}
+	u.mu.Lock()
+	report := u.report
+	u.mu.Unlock()
+	if report == nil {
+		http.Error(w, "the run has not finished yet", http.StatusNotFound)
+		return
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="discord-purge-report.json"`)
+	w.Write(data)
+}
+
+const webStyle = `
+body { font-family: system-ui, sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; }
+fieldset { margin-bottom: 1.5em; }
+.list { max-height: 22em; overflow-y: auto; border: 1px solid #ccc; padding: .5em; }
+.list label { display: block; }
+.detail { color: #777; }
+table { border-collapse: collapse; }
+td, th { padding: .25em 1em .25em 0; text-align: left; }
+.warn { color: #b00; }
+`
+
+var webIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Discord Purge</title><style>` + webStyle + `</style></head>
+<body>
+<h1>Discord Purge</h1>
+<p>Signed in as <b>{{.Username}}</b>{{if .Profile}} — starting from profile <b>{{.Profile}}</b>{{end}}.</p>
+<form method="post" action="/start">
+<input type="hidden" name="csrf" value="{{.CSRF}}">
+
+<fieldset><legend>Scope</legend>
+<label><input type="radio" name="mode" value="exclude" {{if not .IncludeMode}}checked{{end}}> Purge everything <b>except</b> the ticked servers and DMs</label><br>
+<label><input type="radio" name="mode" value="include" {{if .IncludeMode}}checked{{end}}> Purge <b>only</b> the ticked servers and DMs</label><br><br>
+<input type="search" id="filter" placeholder="Filter by name…" oninput="filterLists(this.value)">
+</fieldset>
+
+{{if .ProfileRules}}<fieldset><legend>Also applied from the profile</legend>
+<ul>{{range .ProfileRules}}<li>{{.}}</li>{{end}}</ul>
+</fieldset>{{end}}
+
+<fieldset><legend>Servers ({{len .Guilds}}) <button type="button" onclick="tick('guild', true)">all</button> <button type="button" onclick="tick('guild', false)">none</button></legend>
+<div class="list">{{range .Guilds}}<label><input type="checkbox" name="guild" value="{{.ID}}" {{if .Checked}}checked{{end}} {{if .Locked}}disabled{{end}}> {{.Name}} <span class="detail">{{.ID}}{{if .Locked}} — excluded by the profile{{end}}</span></label>{{else}}<i>No servers.</i>{{end}}</div>
+</fieldset>
+
+<fieldset><legend>Open DMs and group DMs ({{len .DMs}}) <button type="button" onclick="tick('dm', true)">all</button> <button type="button" onclick="tick('dm', false)">none</button></legend>
+<div class="list">{{range .DMs}}<label><input type="checkbox" name="dm" value="{{.ID}}" {{if .Checked}}checked{{end}} {{if .Locked}}disabled{{end}}> {{.Name}} <span class="detail">{{.ID}}{{if .Locked}} — excluded by the profile{{end}}</span></label>{{else}}<i>No open DMs.</i>{{end}}</div>
+</fieldset>
+
+<fieldset><legend>Relationships ({{len .Relationships}}) — tick to keep all DMs with that person</legend>
+<div class="list">{{range .Relationships}}<label><input type="checkbox" name="recipient" value="{{.ID}}" {{if .Checked}}checked{{end}}> {{.Name}} <span class="detail">{{.Detail}}</span></label>{{else}}<i>No relationships.</i>{{end}}</div>
+</fieldset>
+
+<fieldset><legend>Phases</legend>
+{{range .Phases}}<label><input type="checkbox" name="phase" value="{{.ID}}" {{if .Checked}}checked{{end}}> {{.ID}} — {{.Name}}</label><br>{{end}}
+</fieldset>
+
+{{if .Checkpoint}}<fieldset><legend>Checkpoint</legend>
+<label><input type="checkbox" name="resume" value="yes" checked> Resume the run stopped during Phase {{.Checkpoint.Phase}} ({{len .Checkpoint.CompletedGuilds}} servers and {{len .Checkpoint.CompletedDMChannels}} DM channels already done)</label>
+</fieldset>{{end}}
+
+<p class="warn"><label><input type="checkbox" name="confirm" value="yes" required> I understand that deleted messages and reactions cannot be restored.</label></p>
+<button type="submit">Start purge</button>
+</form>
+<script>
+function tick(name, on) {
+  document.querySelectorAll('input[name="' + name + '"]').forEach(function (box) {
+    if (!box.disabled && box.parentElement.style.display !== 'none') box.checked = on;
+  });
+}
+function filterLists(text) {
+  text = text.toLowerCase();
+  document.querySelectorAll('.list label').forEach(function (row) {
+    row.style.display = row.textContent.toLowerCase().indexOf(text) >= 0 ? '' : 'none';
+  });
+}
+</script>
+</body></html>`))
+
+var webProgressTemplate = template.Must(template.New("progress").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Discord Purge — progress</title><style>` + webStyle + `</style></head>
+<body>
+<h1>Discord Purge</h1>
+<p id="state">Loading…</p>
+<table><thead><tr><th>Phase</th><th>State</th><th>Items</th><th>Work</th></tr></thead><tbody id="phases"></tbody></table>
+<p>Messages deleted: <b id="messages">0</b> · Reactions removed: <b id="reactions">0</b> · Rate limits: <b id="ratelimits">0</b> · <span id="eta"></span></p>
+<p id="current"></p>
+<p id="wait" class="warn"></p>
+<p id="report"></p>
+<script>
+function text(id, value) { document.getElementById(id).textContent = value; }
+function refresh() {
+  fetch('/status').then(function (r) { return r.json(); }).then(function (s) {
+    text('state', s.halted ? 'Stopped: ' + s.halt_reason : s.finished ? 'Finished.' : s.running ? 'Running…' : 'Starting…');
+    var rows = document.getElementById('phases');
+    rows.textContent = '';
+    (s.phases || []).forEach(function (p) {
+      var tr = rows.insertRow();
+      tr.insertCell().textContent = p.id + ' — ' + p.label;
+      tr.insertCell().textContent = p.state;
+      tr.insertCell().textContent = p.total ? p.done + ' / ' + p.total + (p.skipped ? ' (' + p.skipped + ' skipped)' : '') : '';
+      var unit = p.id === '3' ? ' channels' : ' messages';
+      tr.insertCell().textContent = p.estimate ? p.work + ' / ~' + p.estimate + unit : (p.work ? p.work + unit : '');
+    });
+    text('eta', s.eta_seconds >= 0 ? 'ETA ' + Math.round(s.eta_seconds / 60) + ' min' : '');
+    text('messages', s.messages_deleted);
+    text('reactions', s.reactions_removed);
+    text('ratelimits', s.rate_limits);
+    text('current', s.current_item ? 'Working on: ' + s.current_item : '');
+    var wait = (new Date(s.rate_limited_until) - new Date()) / 1000;
+    text('wait', wait > 0 ? 'Rate limited on ' + s.rate_limit_route + ', waiting ' + wait.toFixed(0) + 's…' : '');
+    if (s.report_ready) {
+      document.getElementById('report').innerHTML = '<a href="/report">Download the report (JSON)</a>';
+    } else {
+      setTimeout(refresh, 1000);
+    }
+  }).catch(function () { setTimeout(refresh, 3000); });
+}
+refresh();
+</script>
+</body></html>`))
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFormOptionsKeepsProfileRules(t *testing.T) {
+	base := PurgeOptions{
+		ExcludedGuildIDs:     map[string]bool{"g1": true},
+		ExcludedDMChannelIDs: map[string]bool{"d1": true},
+		ExcludedChannelIDs:   map[string]bool{"c1": true},
+		ExcludedDMRecipients: map[string]bool{"u1": true},
+		IncludedChannelIDs:   map[string]bool{"c2": true},
+	}
+	u := &webUI{base: base}
+	form := func(values url.Values) *http.Request {
+		return &http.Request{PostForm: values}
+	}
+
+	except := u.formOptions(form(url.Values{"mode": {"exclude"}, "guild": {"g2"}, "dm": {"d2"}, "recipient": {"u2"}}))
+	if want := map[string]bool{"g1": true, "g2": true}; !reflect.DeepEqual(except.ExcludedGuildIDs, want) {
+		t.Errorf("except mode: excluded guilds = %v, want %v", except.ExcludedGuildIDs, want)
+	}
+	if want := map[string]bool{"d1": true, "d2": true}; !reflect.DeepEqual(except.ExcludedDMChannelIDs, want) {
+		t.Errorf("except mode: excluded DMs = %v, want %v", except.ExcludedDMChannelIDs, want)
+	}
+	if want := map[string]bool{"u1": true, "u2": true}; !reflect.DeepEqual(except.ExcludedDMRecipients, want) {
+		t.Errorf("except mode: excluded recipients = %v, want %v", except.ExcludedDMRecipients, want)
+	}
+	if !except.ExcludedChannelIDs["c1"] {
+		t.Error("except mode dropped the profile's excluded channel")
+	}
+	if except.includeMode() {
+		t.Error("except mode kept the profile's included channels")
+	}
+
+	only := u.formOptions(form(url.Values{"mode": {"include"}, "guild": {"g2"}}))
+	if !only.IncludedGuildIDs["g2"] || !only.IncludedChannelIDs["c2"] {
+		t.Errorf("only mode: included guilds %v, channels %v", only.IncludedGuildIDs, only.IncludedChannelIDs)
+	}
+	if !only.ExcludedGuildIDs["g1"] || !only.ExcludedDMChannelIDs["d1"] {
+		t.Error("only mode dropped the profile's exclusions")
+	}
+	if len(u.base.ExcludedGuildIDs) != 1 || len(u.base.ExcludedDMChannelIDs) != 1 {
+		t.Error("formOptions modified the base options")
+	}
+}